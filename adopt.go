@@ -0,0 +1,248 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// recordProcessIdentity 记录进程的创建时间和命令行，用于守护进程重启后识别进程
+func recordProcessIdentity(p *Process) {
+	proc, err := process.NewProcess(int32(p.PID))
+	if err != nil {
+		return
+	}
+
+	if createTime, err := proc.CreateTime(); err == nil {
+		p.ProcStartTime = createTime
+	}
+	// 通过 shim 设置资源限制或 cgroup 的进程此时可能还未 exec 应用，命令行不可靠，只依据创建时间识别
+	p.Cmdline = ""
+	if p.Limits != nil || p.CgroupPath != "" {
+		return
+	}
+	if cmdline, err := proc.Cmdline(); err == nil {
+		p.Cmdline = cmdline
+	}
+}
+
+// matchesProcessIdentity 检查 PID 对应的进程是否仍是之前启动的进程，避免接管被复用的 PID
+func matchesProcessIdentity(p *Process) bool {
+	if p.PID <= 0 {
+		return false
+	}
+
+	proc, err := process.NewProcess(int32(p.PID))
+	if err != nil {
+		return false
+	}
+
+	if running, _ := proc.IsRunning(); !running {
+		return false
+	}
+	if status, err := proc.Status(); err == nil && len(status) > 0 && status[0] == process.Zombie {
+		return false
+	}
+
+	createTime, err := proc.CreateTime()
+	if err != nil {
+		return false
+	}
+
+	if p.ProcStartTime > 0 {
+		if createTime != p.ProcStartTime {
+			return false
+		}
+	} else {
+		// 旧的状态文件没有记录创建时间，以启动时间近似判断
+		diff := createTime - p.StartTime.UnixMilli()
+		if diff < -2000 || diff > 2000 {
+			return false
+		}
+	}
+
+	if p.Cmdline != "" {
+		cmdline, err := proc.Cmdline()
+		if err != nil || cmdline != p.Cmdline {
+			return false
+		}
+	}
+
+	return true
+}
+
+// recoverProcesses 守护进程启动后接管仍在运行的进程，并重启应在运行但已停止的进程
+func (pm *ProcessManager) recoverProcesses() {
+	pm.mutex.RLock()
+	processes := make([]*Process, 0, len(pm.processes))
+	for _, p := range pm.processes {
+		processes = append(processes, p)
+	}
+	pm.mutex.RUnlock()
+
+	var pending []*Process
+	for _, p := range sortProcessesByDependency(processes) {
+		if p.isRunning() && p.PID > 0 {
+			pm.adoptProcess(p)
+			continue
+		}
+
+		// 如果进程应该在运行但当前已停止，尝试重启
+		// 这处理了系统重启后的自动恢复场景
+		// 任务只在显式触发时运行，不参与自动恢复
+		if p.Status == StatusStopped && !p.StartTime.IsZero() && p.Type != AppTypeJob {
+			pending = append(pending, p)
+		}
+	}
+
+	// 延迟一小段时间后按依赖顺序依次重启
+	if len(pending) > 0 {
+		go func() {
+			time.Sleep(2 * time.Second)
+			pm.startInDependencyOrder(pending)
+		}()
+	}
+}
+
+// startInDependencyOrder 依次启动已按依赖排序的进程，依赖未在运行的进程不启动并标记为 errored
+func (pm *ProcessManager) startInDependencyOrder(processes []*Process) {
+	for _, p := range processes {
+		if dep := pm.unavailableDependency(p); dep != "" {
+			p.mutex.Lock()
+			p.Status = StatusErrored
+			pm.recordEvent(p, "start", fmt.Sprintf("依赖 '%s' 未在运行，跳过启动", dep))
+			p.mutex.Unlock()
+			continue
+		}
+
+		err := pm.startProcessInstance(p)
+		if err != nil {
+			p.mutex.Lock()
+			p.Status = startFailedStatus(err)
+			p.mutex.Unlock()
+		}
+	}
+	pm.saveProcesses()
+}
+
+// unavailableDependency 返回进程第一个不存在或未在运行的依赖，依赖都已满足时返回空字符串
+// 任务类型的依赖空闲（one-time）时视为满足
+func (pm *ProcessManager) unavailableDependency(p *Process) string {
+	pm.mutex.RLock()
+	defer pm.mutex.RUnlock()
+
+	for _, name := range p.DependsOn {
+		dep := pm.findProcess(name)
+		if dep == nil {
+			return name
+		}
+		dep.mutex.Lock()
+		ready := dep.isRunning() || (dep.Type == AppTypeJob && dep.Status == StatusOneTime)
+		dep.mutex.Unlock()
+		if !ready {
+			return name
+		}
+	}
+	return ""
+}
+
+// adoptProcess 接管上一个守护进程启动的进程
+func (pm *ProcessManager) adoptProcess(p *Process) {
+	p.mutex.Lock()
+
+	// 子进程仍直接写入原来的日志文件，这里重新打开只用于记录守护进程自身的输出
+	if logFile, err := os.OpenFile(p.LogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
+		p.logWriter = logFile
+	}
+	if errorFile, err := os.OpenFile(p.ErrorFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
+		p.errorWriter = errorFile
+	}
+
+	p.oomKills = readOOMKills(p.CgroupPath)
+	if p.WatchdogTimeout > 0 {
+		// 应用仍使用原来的 NOTIFY_SOCKET，重新在同一路径上监听
+		if _, err := pm.openWatchdog(p); err != nil {
+			pm.recordEvent(p, "watchdog", err.Error())
+		}
+	}
+	pm.recordEvent(p, "adopt", fmt.Sprintf("守护进程重启后接管进程 (PID: %d)", p.PID))
+	p.mutex.Unlock()
+
+	if p.Type == AppTypeJob {
+		go pm.watchAdoptedJob(p)
+	} else {
+		go pm.watchProcess(p)
+	}
+}
+
+// waitAdoptedProcess 轮询等待接管的进程退出
+func (pm *ProcessManager) waitAdoptedProcess(p *Process) error {
+	for {
+		time.Sleep(1 * time.Second)
+
+		p.mutex.RLock()
+		alive := matchesProcessIdentity(p)
+		p.mutex.RUnlock()
+
+		if !alive {
+			return fmt.Errorf("接管的进程已退出，无法获取退出码")
+		}
+	}
+}
+
+// watchAdoptedJob 等待接管的任务运行结束并记录结果
+func (pm *ProcessManager) watchAdoptedJob(p *Process) {
+	err := pm.waitAdoptedProcess(p)
+
+	p.mutex.Lock()
+	if p.Status != StatusStopping && p.Status != StatusStopped {
+		run := &JobRun{
+			ID:        p.RunCount,
+			Trigger:   "adopted",
+			StartTime: p.StartTime,
+		}
+		if runDir, dirErr := pm.appDataDir("jobs", p.Name); dirErr == nil {
+			run.LogFile = filepath.Join(runDir, fmt.Sprintf("%d.log", p.RunCount))
+			run.ErrorFile = filepath.Join(runDir, fmt.Sprintf("%d-error.log", p.RunCount))
+		}
+		pm.completeJobRun(p, run, -1, err.Error())
+	}
+	closeFiles([]*os.File{p.logWriter, p.errorWriter})
+	p.logWriter = nil
+	p.errorWriter = nil
+	p.mutex.Unlock()
+
+	pm.saveProcesses()
+}
+
+// stopAdoptedProcess 停止接管的进程（调用方需持有 p.mutex）
+func (pm *ProcessManager) stopAdoptedProcess(p *Process) {
+	if !matchesProcessIdentity(p) {
+		return
+	}
+
+	proc, err := os.FindProcess(p.PID)
+	if err != nil {
+		return
+	}
+
+	if proc.Signal(syscall.SIGTERM) != nil {
+		proc.Kill()
+		return
+	}
+
+	// 等待5秒让进程优雅退出
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if !matchesProcessIdentity(p) {
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	proc.Kill()
+}
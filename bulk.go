@@ -0,0 +1,240 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// resolveTargets 解析目标选择器，返回匹配的进程（按依赖顺序）
+// 支持 all、名称、ID、ID 范围 (1-5)、通配符 (api-*) 以及逗号分隔的组合，
+// 并按命名空间和标签选择器过滤；只指定选择器时匹配全部应用中满足选择器的应用
+func (pm *ProcessManager) resolveTargets(selector, labelSelector, namespace string) ([]*Process, error) {
+	reqs, err := parseLabelSelector(labelSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	// 目标和选择器都为空时不默认作用于全部应用，全部应用需显式指定 all
+	if selector == "" {
+		if labelSelector == "" && namespace == "" {
+			return nil, fmt.Errorf("请指定目标进程或选择器 (-l/--namespace)")
+		}
+		selector = "all"
+	}
+
+	pm.mutex.RLock()
+	defer pm.mutex.RUnlock()
+
+	matched := make(map[int]*Process)
+	for _, token := range strings.Split(selector, ",") {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+
+		found := pm.matchTarget(token)
+		if len(found) == 0 {
+			return nil, fmt.Errorf("未找到进程: %s", token)
+		}
+		for _, p := range found {
+			if p.matchesSelector(namespace, reqs) {
+				matched[p.ID] = p
+			}
+		}
+	}
+
+	if len(matched) == 0 {
+		return nil, fmt.Errorf("没有匹配选择器的进程")
+	}
+
+	targets := make([]*Process, 0, len(matched))
+	for _, p := range matched {
+		targets = append(targets, p)
+	}
+	return sortProcessesByDependency(targets), nil
+}
+
+// matchTarget 匹配单个选择器片段（调用方需持有 pm.mutex）
+func (pm *ProcessManager) matchTarget(token string) []*Process {
+	var found []*Process
+
+	if token == "all" {
+		for _, p := range pm.processes {
+			found = append(found, p)
+		}
+		return found
+	}
+
+	// 精确ID
+	if id, err := strconv.Atoi(token); err == nil {
+		if p, exists := pm.processes[id]; exists {
+			return []*Process{p}
+		}
+	}
+
+	// 精确名称
+	for _, p := range pm.processes {
+		if p.Name == token {
+			found = append(found, p)
+		}
+	}
+	if len(found) > 0 {
+		return found
+	}
+
+	// ID范围，按已有进程的 ID 判断，不逐个遍历范围内的 ID
+	if bounds := strings.SplitN(token, "-", 2); len(bounds) == 2 {
+		low, errLow := strconv.Atoi(bounds[0])
+		high, errHigh := strconv.Atoi(bounds[1])
+		if errLow == nil && errHigh == nil {
+			for _, p := range pm.processes {
+				if low <= p.ID && p.ID <= high {
+					found = append(found, p)
+				}
+			}
+			return found
+		}
+	}
+
+	// 名称通配符
+	if strings.ContainsAny(token, "*?[") {
+		for _, p := range pm.processes {
+			if ok, _ := path.Match(token, p.Name); ok {
+				found = append(found, p)
+			}
+		}
+	}
+
+	return found
+}
+
+// runBulk 以指定并行度对每个目标执行操作，结果与目标一一对应
+func runBulk(targets []*Process, parallel int, action func(p *Process) (string, error)) []string {
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	results := make([]string, len(targets))
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+
+	for i, p := range targets {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, p *Process) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			msg, err := action(p)
+			if err != nil {
+				results[i] = fmt.Sprintf("ERROR: '%s': %v", p.Name, err)
+			} else {
+				results[i] = "SUCCESS: " + msg
+			}
+		}(i, p)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// handleBulkCommand 处理 STOP/RESTART/DELETE/PAUSE/RESUME/SIGNAL 命令，每个目标一行结果
+// extra 为命令的附加参数，如 SIGNAL 的信号名称
+func (pm *ProcessManager) handleBulkCommand(command, selector, labelSelector, namespace string, parallel int, extra ...string) string {
+	targets, err := pm.resolveTargets(selector, labelSelector, namespace)
+	if err != nil {
+		return "ERROR: " + err.Error()
+	}
+
+	var results []string
+	switch command {
+	case "STOP":
+		// 停止时按依赖关系逆序
+		reverseProcesses(targets)
+		results = runBulk(targets, parallel, func(p *Process) (string, error) {
+			if p.Status == StatusStopped || p.Status == StatusBuildFailed || (p.Status == StatusErrored && p.Type != AppTypeJob) {
+				return fmt.Sprintf("'%s' 当前状态为 %s，跳过", p.Name, p.Status), nil
+			}
+			if err := pm.stopProcessInstance(p); err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("停止 '%s'", p.Name), nil
+		})
+
+	case "RESTART":
+		// 指定 --update-env 时 extra 依次为要切换的环境配置、-e 指定的变量和调用方的环境变量 (JSON)
+		var env, callerEnv map[string]string
+		if len(extra) >= 3 {
+			if err := json.Unmarshal([]byte(extra[1]), &env); err != nil {
+				return "ERROR: " + err.Error()
+			}
+			if err := json.Unmarshal([]byte(extra[2]), &callerEnv); err != nil {
+				return "ERROR: " + err.Error()
+			}
+		}
+		results = runBulk(targets, parallel, func(p *Process) (string, error) {
+			if len(extra) > 0 {
+				if err := pm.updateProcessEnv(p, extra[0], env, callerEnv); err != nil {
+					return "", err
+				}
+			}
+			if err := pm.restartProcessInstance(p); err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("重启 '%s'", p.Name), nil
+		})
+
+	case "DELETE":
+		reverseProcesses(targets)
+		results = runBulk(targets, parallel, func(p *Process) (string, error) {
+			if err := pm.deleteProcessInstance(p); err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("删除 '%s' (ID: %d)", p.Name, p.ID), nil
+		})
+
+	case "PAUSE":
+		results = runBulk(targets, parallel, func(p *Process) (string, error) {
+			if err := pm.pauseProcessInstance(p); err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("暂停 '%s'", p.Name), nil
+		})
+
+	case "RESUME":
+		results = runBulk(targets, parallel, func(p *Process) (string, error) {
+			if err := pm.resumeProcessInstance(p); err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("恢复 '%s'", p.Name), nil
+		})
+
+	case "SIGNAL":
+		if len(extra) == 0 {
+			return "ERROR: 未指定信号"
+		}
+		sig, sigName, err := parseSignal(extra[0])
+		if err != nil {
+			return "ERROR: " + err.Error()
+		}
+		results = runBulk(targets, parallel, func(p *Process) (string, error) {
+			if err := pm.signalProcessInstance(p, sig, sigName); err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("向 '%s' 发送 %s", p.Name, sigName), nil
+		})
+	}
+
+	return strings.Join(results, "\n")
+}
+
+// reverseProcesses 原地反转进程列表
+func reverseProcesses(processes []*Process) {
+	for i, j := 0, len(processes)-1; i < j; i, j = i+1, j-1 {
+		processes[i], processes[j] = processes[j], processes[i]
+	}
+}
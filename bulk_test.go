@@ -0,0 +1,67 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResolveTargets(t *testing.T) {
+	pm := &ProcessManager{processes: map[int]*Process{
+		1:   {ID: 1, Name: "api-1", Namespace: "prod", Labels: map[string]string{"team": "payments"}},
+		2:   {ID: 2, Name: "api-2", Namespace: "prod"},
+		3:   {ID: 3, Name: "worker", Namespace: "staging", Labels: map[string]string{"team": "payments"}},
+		5:   {ID: 5, Name: "db", Namespace: "prod"},
+		6:   {ID: 6, Name: "10-20"},
+		100: {ID: 100, Name: "cron"},
+	}}
+
+	tests := []struct {
+		selector  string
+		labels    string
+		namespace string
+		want      []int
+		wantErr   bool
+	}{
+		{"all", "", "", []int{1, 2, 3, 5, 6, 100}, false},
+		{"2", "", "", []int{2}, false},
+		{"db", "", "", []int{5}, false},
+		{"api-*", "", "", []int{1, 2}, false},
+		{"api-?", "", "", []int{1, 2}, false},
+		{"1,worker, db", "", "", []int{1, 3, 5}, false},
+		{"1,1", "", "", []int{1}, false},
+
+		// ID 范围只匹配已有的进程，范围很大时也不会逐个遍历
+		{"1-5", "", "", []int{1, 2, 3, 5}, false},
+		{"4-4", "", "", nil, true},
+		{"3-1", "", "", nil, true},
+		{"0-9223372036854775807", "", "", []int{1, 2, 3, 5, 6, 100}, false},
+		{"-5-3", "", "", nil, true},
+		// 名称优先于 ID 范围
+		{"10-20", "", "", []int{6}, false},
+
+		{"", "team=payments", "", []int{1, 3}, false},
+		{"", "", "prod", []int{1, 2, 5}, false},
+		{"api-*", "", "staging", nil, true},
+		{"all", "team=payments", "prod", []int{1}, false},
+
+		{"", "", "", nil, true},
+		{"missing", "", "", nil, true},
+		{"1,missing", "", "", nil, true},
+		{"all", "=x", "", nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := pm.resolveTargets(tt.selector, tt.labels, tt.namespace)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("resolveTargets(%q, %q, %q) error = %v, wantErr %v", tt.selector, tt.labels, tt.namespace, err, tt.wantErr)
+			continue
+		}
+		var ids []int
+		for _, p := range got {
+			ids = append(ids, p.ID)
+		}
+		if !reflect.DeepEqual(ids, tt.want) {
+			t.Errorf("resolveTargets(%q, %q, %q) = %v, want %v", tt.selector, tt.labels, tt.namespace, ids, tt.want)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 每个 CPU 核心对应的 cpu.max 周期（微秒）
+const cgroupCPUPeriod = 100000
+
+// CgroupConfig 应用的 cgroup v2 资源限制
+type CgroupConfig struct {
+	MemoryMax string  `json:"memory_max,omitempty" yaml:"memory_max,omitempty"` // 如 512M、2G 或 max
+	CPUMax    float64 `json:"cpu_max,omitempty" yaml:"cpu_max,omitempty"`       // CPU 核数，如 1.5
+	PidsMax   int     `json:"pids_max,omitempty" yaml:"pids_max,omitempty"`
+	IOWeight  int     `json:"io_weight,omitempty" yaml:"io_weight,omitempty"` // 1-10000
+}
+
+// files 返回需要写入的 cgroup 接口文件及其内容
+func (c *CgroupConfig) files() (map[string]string, error) {
+	files := make(map[string]string)
+	if c == nil {
+		return files, nil
+	}
+
+	if c.MemoryMax != "" {
+		if c.MemoryMax == "max" {
+			files["memory.max"] = "max"
+		} else {
+			bytes, err := parseByteSize(c.MemoryMax)
+			if err != nil {
+				return nil, fmt.Errorf("cgroup.memory_max: %v", err)
+			}
+			files["memory.max"] = strconv.FormatUint(bytes, 10)
+		}
+	}
+
+	if c.CPUMax < 0 {
+		return nil, fmt.Errorf("cgroup.cpu_max 不能为负数")
+	}
+	if c.CPUMax > 0 {
+		quota := int(c.CPUMax * cgroupCPUPeriod)
+		if quota < 1000 {
+			return nil, fmt.Errorf("cgroup.cpu_max 过小: %v", c.CPUMax)
+		}
+		files["cpu.max"] = fmt.Sprintf("%d %d", quota, cgroupCPUPeriod)
+	}
+
+	if c.PidsMax < 0 {
+		return nil, fmt.Errorf("cgroup.pids_max 不能为负数")
+	}
+	if c.PidsMax > 0 {
+		files["pids.max"] = strconv.Itoa(c.PidsMax)
+	}
+
+	if c.IOWeight != 0 {
+		if c.IOWeight < 1 || c.IOWeight > 10000 {
+			return nil, fmt.Errorf("cgroup.io_weight 必须在 1-10000 之间")
+		}
+		files["io.weight"] = fmt.Sprintf("default %d", c.IOWeight)
+	}
+
+	return files, nil
+}
+
+// parseByteSize 解析带单位的字节数，如 512M、1.5G、1024
+func parseByteSize(input string) (uint64, error) {
+	value := strings.ToUpper(strings.TrimSpace(input))
+	value = strings.TrimSuffix(value, "B")
+	value = strings.TrimSuffix(value, "I")
+
+	multiplier := 1.0
+	if n := len(value); n > 0 {
+		switch value[n-1] {
+		case 'K':
+			multiplier = 1 << 10
+		case 'M':
+			multiplier = 1 << 20
+		case 'G':
+			multiplier = 1 << 30
+		case 'T':
+			multiplier = 1 << 40
+		}
+		if multiplier > 1 {
+			value = value[:n-1]
+		}
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || number <= 0 {
+		return 0, fmt.Errorf("无效的大小: %s", input)
+	}
+	return uint64(number * multiplier), nil
+}
+
+// cgroupName 返回应用在父 cgroup 下的目录名，. 和 .. 等会指向父 cgroup 之外的名称以及与接口文件冲突的名称返回错误
+func cgroupName(name string) (string, error) {
+	if err := validateAppName(name); err != nil {
+		return "", err
+	}
+	if strings.HasPrefix(name, "cgroup.") {
+		return "", fmt.Errorf("应用名称 '%s' 与 cgroup 接口文件冲突", name)
+	}
+	return name, nil
+}
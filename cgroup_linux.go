@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// cgroup v2 挂载点
+const cgroupMountPoint = "/sys/fs/cgroup"
+
+// 需要为应用启用的 cgroup 控制器
+var cgroupControllers = []string{"memory", "cpu", "pids", "io"}
+
+// initCgroups 检测 cgroup v2 是否可写，并准备存放应用 cgroup 的父目录
+// 守护进程位于根 cgroup 时使用 /sys/fs/cgroup/gopm2；位于受委派的子树（如带 Delegate=yes 的 systemd 服务）时，
+// 先将自身移入 daemon 子 cgroup，再在同级的 apps 下为应用创建 cgroup；未受委派的 cgroup 不做任何修改
+func (pm *ProcessManager) initCgroups() {
+	if _, err := os.Stat(filepath.Join(cgroupMountPoint, "cgroup.controllers")); err != nil {
+		return
+	}
+
+	self, err := currentCgroup()
+	if err != nil {
+		return
+	}
+
+	var base, own string
+	if self == "/" {
+		enableCgroupControllers(cgroupMountPoint)
+		base = filepath.Join(cgroupMountPoint, "gopm2")
+	} else {
+		own = filepath.Join(cgroupMountPoint, self)
+		if filepath.Base(own) == "daemon" {
+			own = filepath.Dir(own)
+		}
+		if !isDelegatedCgroup(own) {
+			return
+		}
+
+		// 有进程的 cgroup 不能为子 cgroup 启用控制器，守护进程需移入叶子节点
+		leaf := filepath.Join(own, "daemon")
+		if err := os.MkdirAll(leaf, 0755); err != nil {
+			return
+		}
+		if err := os.WriteFile(filepath.Join(leaf, "cgroup.procs"), []byte(strconv.Itoa(os.Getpid())), 0644); err != nil {
+			return
+		}
+		enableCgroupControllers(own)
+		base = filepath.Join(own, "apps")
+	}
+
+	// 至少需要 memory 控制器，否则退回原来的 cgroup 并不启用资源隔离
+	os.MkdirAll(base, 0755)
+	enableCgroupControllers(base)
+	controllers, err := os.ReadFile(filepath.Join(base, "cgroup.controllers"))
+	if err != nil || !strings.Contains(" "+strings.TrimSpace(string(controllers))+" ", " memory ") {
+		if own != "" {
+			os.WriteFile(filepath.Join(own, "cgroup.procs"), []byte(strconv.Itoa(os.Getpid())), 0644)
+		}
+		return
+	}
+	pm.cgroupBase = base
+}
+
+// currentCgroup 读取当前进程所在的 cgroup v2 路径
+func currentCgroup() (string, error) {
+	data, err := os.ReadFile("/proc/self/cgroup")
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "0::") {
+			return strings.TrimPrefix(line, "0::"), nil
+		}
+	}
+	return "", fmt.Errorf("未找到 cgroup v2")
+}
+
+// isDelegatedCgroup 判断 cgroup 是否委派给了守护进程：systemd 为 Delegate=yes 的单元设置
+// trusted.delegate 或 user.delegate 扩展属性，较早的版本只将目录的属主改为服务用户
+func isDelegatedCgroup(dir string) bool {
+	buf := make([]byte, 8)
+	for _, attr := range []string{"trusted.delegate", "user.delegate"} {
+		if n, err := unix.Getxattr(dir, attr, buf); err == nil && string(buf[:n]) == "1" {
+			return true
+		}
+	}
+
+	var st unix.Stat_t
+	if err := unix.Stat(filepath.Join(dir, "cgroup.procs"), &st); err != nil {
+		return false
+	}
+	uid := os.Getuid()
+	return uid != 0 && int(st.Uid) == uid
+}
+
+// enableCgroupControllers 为子 cgroup 启用可用的控制器，忽略不可用的控制器
+func enableCgroupControllers(dir string) {
+	for _, controller := range cgroupControllers {
+		os.WriteFile(filepath.Join(dir, "cgroup.subtree_control"), []byte("+"+controller), 0644)
+	}
+}
+
+// setupCgroup 为配置了 cgroup 资源限制的应用创建 cgroup 并写入限制，未配置或 cgroup 不可用时返回空字符串（调用方需持有 p.mutex）
+// 未配置的应用直接启动，不经过 shim，也不移出守护进程所在的 cgroup
+func (pm *ProcessManager) setupCgroup(p *Process) (string, error) {
+	files, err := p.Cgroup.files()
+	if err != nil || len(files) == 0 {
+		return "", err
+	}
+
+	if pm.cgroupBase == "" {
+		pm.recordEvent(p, "cgroup", "cgroup v2 不可用或不可写，未应用 cgroup 资源限制")
+		return "", nil
+	}
+
+	name, err := cgroupName(p.Name)
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(pm.cgroupBase, name)
+	if filepath.Dir(dir) != pm.cgroupBase {
+		return "", fmt.Errorf("应用名称 '%s' 不能用作 cgroup 名称", p.Name)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("创建 cgroup 失败: %v", err)
+	}
+
+	for name, value := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(value), 0644); err != nil {
+			return "", fmt.Errorf("设置 cgroup %s 失败: %v", name, err)
+		}
+	}
+
+	p.CgroupPath = dir
+	p.oomKills = readOOMKills(dir)
+	return dir, nil
+}
+
+// cleanupCgroup 结束 cgroup 中残留的进程并删除 cgroup（调用方需持有 p.mutex）
+func (pm *ProcessManager) cleanupCgroup(p *Process) {
+	if p.CgroupPath == "" {
+		return
+	}
+	// 只处理守护进程创建的应用 cgroup，避免向父 cgroup 写入 cgroup.kill
+	if pm.cgroupBase == "" || filepath.Dir(p.CgroupPath) != pm.cgroupBase {
+		p.CgroupPath = ""
+		return
+	}
+
+	os.WriteFile(filepath.Join(p.CgroupPath, "cgroup.kill"), []byte("1"), 0644)
+	for i := 0; i < 20; i++ {
+		if err := os.Remove(p.CgroupPath); err == nil || os.IsNotExist(err) {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	p.CgroupPath = ""
+}
+
+// readOOMKills 读取 cgroup 中被 OOM killer 终止的进程数
+func readOOMKills(dir string) int {
+	if dir == "" {
+		return 0
+	}
+	file, err := os.Open(filepath.Join(dir, "memory.events"))
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == "oom_kill" {
+			count, _ := strconv.Atoi(fields[1])
+			return count
+		}
+	}
+	return 0
+}
+
+// readCgroupStats 读取 cgroup 的内存用量和累计 CPU 时间（微秒）
+func readCgroupStats(dir string) (uint64, uint64, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "memory.current"))
+	if err != nil {
+		return 0, 0, err
+	}
+	memory, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	data, err = os.ReadFile(filepath.Join(dir, "cpu.stat"))
+	if err != nil {
+		return 0, 0, err
+	}
+	var cpuUsec uint64
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == "usage_usec" {
+			cpuUsec, _ = strconv.ParseUint(fields[1], 10, 64)
+		}
+	}
+	return memory, cpuUsec, nil
+}
@@ -0,0 +1,33 @@
+//go:build !linux
+
+package main
+
+import "fmt"
+
+// initCgroups 非 Linux 系统没有 cgroup
+func (pm *ProcessManager) initCgroups() {}
+
+// setupCgroup 非 Linux 系统不支持 cgroup，忽略 cgroup 配置（调用方需持有 p.mutex）
+func (pm *ProcessManager) setupCgroup(p *Process) (string, error) {
+	files, err := p.Cgroup.files()
+	if err != nil {
+		return "", err
+	}
+	if len(files) > 0 {
+		pm.recordEvent(p, "cgroup", "当前系统不支持 cgroup，未应用 cgroup 资源限制")
+	}
+	return "", nil
+}
+
+// cleanupCgroup 非 Linux 系统无需处理（调用方需持有 p.mutex）
+func (pm *ProcessManager) cleanupCgroup(p *Process) {}
+
+// readOOMKills 非 Linux 系统始终返回 0
+func readOOMKills(dir string) int {
+	return 0
+}
+
+// readCgroupStats 非 Linux 系统不支持
+func readCgroupStats(dir string) (uint64, uint64, error) {
+	return 0, 0, fmt.Errorf("当前系统不支持 cgroup")
+}
@@ -3,9 +3,11 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"text/tabwriter"
@@ -15,6 +17,22 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// 批量命令等待守护进程响应的超时时间
+const bulkCommandTimeout = 10 * time.Minute
+
+// startCommandTimeout 返回 START 命令等待守护进程响应的超时时间
+// 守护进程在响应前会执行 pre_start 钩子并编译 Go 应用，超时时间需长于钩子超时
+func startCommandTimeout(config AppConfig) time.Duration {
+	timeout := bulkCommandTimeout
+	if hookTimeout, err := time.ParseDuration(config.HookTimeout); err == nil && hookTimeout+time.Minute > timeout {
+		timeout = hookTimeout + time.Minute
+	}
+	return timeout
+}
+
+// 单脚本启动时随 START 命令传递的调用方环境变量
+var clientEnvVars = []string{"PATH", "LANG", "LC_ALL", "TZ"}
+
 var (
 	version = "1.0.1"
 	pm      *ProcessManager
@@ -47,17 +65,19 @@ func init() {
 
 	// start 命令
 	var startCmd = &cobra.Command{
-		Use:   "start [script|config]",
+		Use:   "start [script|config] [-- args...]",
 		Short: "启动应用或配置文件",
-		Long:  "启动一个脚本文件或从配置文件启动多个应用",
-		Args:  cobra.MinimumNArgs(1),
+		Long:  "启动一个脚本文件、通过 --shell 启动一条 shell 命令，或从配置文件启动多个应用，-- 之后的参数原样传给脚本",
+		Args:  cobra.ArbitraryArgs,
 		Run:   runStart,
 	}
 
 	startCmd.Flags().StringP("name", "n", "", "应用名称")
 	startCmd.Flags().StringArrayP("args", "a", []string{}, "传递给脚本的参数")
+	startCmd.Flags().StringP("shell", "", "", "通过 /bin/sh -c 执行的命令，如 \"npm run dev\"")
 	startCmd.Flags().StringP("cwd", "c", "", "工作目录")
-	startCmd.Flags().StringToStringP("env", "e", map[string]string{}, "环境变量 (key=value)")
+	startCmd.Flags().StringArrayP("env", "e", []string{}, "环境变量 (key=value，多个用逗号分隔)，或配置文件中的环境配置名称 (如 production)")
+	startCmd.Flags().StringArrayP("env-file", "", []string{}, "环境变量文件 (.env)，可多次指定")
 	startCmd.Flags().IntP("instances", "i", 1, "实例数量")
 	startCmd.Flags().StringP("exec-mode", "x", "fork", "执行模式 (fork|cluster)")
 	startCmd.Flags().BoolP("watch", "w", false, "启用文件监控")
@@ -66,32 +86,108 @@ func init() {
 	startCmd.Flags().StringP("error", "", "", "错误日志文件路径")
 	startCmd.Flags().IntP("max-restarts", "", 15, "最大重启次数")
 	startCmd.Flags().StringP("min-uptime", "", "1s", "最小运行时间")
+	startCmd.Flags().IntP("port", "", 0, "应用监听的端口，已被占用时拒绝启动")
+	startCmd.Flags().StringP("interpreter", "", "", "解释器 (none 表示直接执行)")
+	startCmd.Flags().StringP("user", "", "", "运行应用的用户")
+	startCmd.Flags().StringP("group", "", "", "运行应用的用户组 (默认: 用户的主组)")
+	startCmd.Flags().StringArrayP("interpreter-args", "", []string{}, "解释器参数")
+	startCmd.Flags().StringP("namespace", "", "", "命名空间 (默认: default)")
+	startCmd.Flags().StringToStringP("label", "", map[string]string{}, "标签 (key=value)")
+	startCmd.Flags().StringP("wait-deps", "", "online", "启动前等待依赖的方式 (none|online|ready)")
+	startCmd.Flags().DurationP("deps-timeout", "", 30*time.Second, "等待依赖就绪的超时时间")
 
 	// stop 命令
 	var stopCmd = &cobra.Command{
-		Use:   "stop <name|id>",
+		Use:   "stop <name|id|all|pattern|config>",
 		Short: "停止应用",
-		Args:  cobra.ExactArgs(1),
+		Long:  "停止应用，支持 all、通配符 (api-*)、逗号分隔列表和ID范围 (1-5)",
+		Args:  cobra.MaximumNArgs(1),
 		Run:   runStop,
 	}
 
 	// restart 命令
 	var restartCmd = &cobra.Command{
-		Use:   "restart <name|id>",
+		Use:   "restart <name|id|all|pattern>",
 		Short: "重启应用",
-		Args:  cobra.ExactArgs(1),
+		Long:  "重启应用，支持 all、通配符 (api-*)、逗号分隔列表和ID范围 (1-5)",
+		Args:  cobra.MaximumNArgs(1),
 		Run:   runRestart,
 	}
 
 	// delete 命令
 	var deleteCmd = &cobra.Command{
-		Use:     "delete <name|id>",
+		Use:     "delete <name|id|all|pattern>",
 		Aliases: []string{"del"},
 		Short:   "删除应用",
-		Args:    cobra.ExactArgs(1),
+		Long:    "删除应用，支持 all、通配符 (api-*)、逗号分隔列表和ID范围 (1-5)",
+		Args:    cobra.MaximumNArgs(1),
 		Run:     runDelete,
 	}
 
+	// pause 命令
+	var pauseCmd = &cobra.Command{
+		Use:   "pause <name|id|all|pattern>",
+		Short: "暂停应用 (SIGSTOP)",
+		Long:  "向应用的进程组发送 SIGSTOP 暂停运行，支持 all、通配符 (api-*)、逗号分隔列表和ID范围 (1-5)",
+		Args:  cobra.MaximumNArgs(1),
+		Run:   runPause,
+	}
+
+	// resume 命令
+	var resumeCmd = &cobra.Command{
+		Use:   "resume <name|id|all|pattern>",
+		Short: "恢复暂停的应用 (SIGCONT)",
+		Long:  "向应用的进程组发送 SIGCONT 恢复运行，支持 all、通配符 (api-*)、逗号分隔列表和ID范围 (1-5)",
+		Args:  cobra.MaximumNArgs(1),
+		Run:   runResume,
+	}
+
+	// signal 命令
+	var signalCmd = &cobra.Command{
+		Use:   "signal <SIG> <name|id|all|pattern>",
+		Short: "向应用发送信号",
+		Long:  "通过守护进程向应用的进程组发送信号，如 HUP、USR1、15，目标写法同 stop",
+		Args:  cobra.RangeArgs(1, 2),
+		Run:   runSignal,
+	}
+
+	for _, c := range []*cobra.Command{stopCmd, restartCmd, deleteCmd, pauseCmd, resumeCmd, signalCmd} {
+		c.Flags().IntP("parallel", "p", 1, "批量操作的并行数")
+	}
+	restartCmd.Flags().BoolP("update-env", "", false, "重启时用当前 shell 刷新 PATH 等从调用方复制的环境变量")
+	restartCmd.Flags().StringArrayP("env", "e", []string{}, "设置环境变量 (key=value) 或切换环境配置 (需配合 --update-env)")
+
+	// set 命令
+	var setCmd = &cobra.Command{
+		Use:   "set <name|id> <key=value>...",
+		Short: "修改应用配置",
+		Long: "修改已保存的应用配置，保留应用的 ID 和重启记录，如 env.LOG_LEVEL=debug、max_restarts=5、args='--port 8080'\n" +
+			"部分字段 (如 env、args、script) 在应用重启后生效；指定 -l/--namespace 时省略应用名称，修改所有匹配的应用",
+		Args: cobra.MinimumNArgs(1),
+		Run:  runSet,
+	}
+	setCmd.Flags().StringArrayP("unset", "", []string{}, "删除环境变量或标签，如 env.DEBUG、labels.tier")
+
+	// run 命令
+	var runCmd = &cobra.Command{
+		Use:   "run <name|id>",
+		Short: "运行一次任务 (type: job)",
+		Long:  "运行一次任务，指定 -l/--namespace 时运行所有匹配的任务",
+		Args:  cobra.MaximumNArgs(1),
+		Run:   runRun,
+	}
+
+	runCmd.Flags().BoolP("wait", "w", false, "等待任务结束并显示结果")
+	runCmd.Flags().DurationP("timeout", "t", 0, "等待超时时间 (0 表示不限制)")
+
+	// jobs 命令
+	var jobsCmd = &cobra.Command{
+		Use:   "jobs [name|id]",
+		Short: "列出任务的调度与运行结果",
+		Args:  cobra.MaximumNArgs(1),
+		Run:   runJobs,
+	}
+
 	// list 命令
 	var listCmd = &cobra.Command{
 		Use:     "list",
@@ -99,12 +195,14 @@ func init() {
 		Short:   "列出所有进程",
 		Run:     runList,
 	}
+	listCmd.Flags().BoolP("json", "", false, "以 JSON 格式输出")
 
 	// logs 命令
 	var logsCmd = &cobra.Command{
 		Use:   "logs <name|id>",
 		Short: "显示日志",
-		Args:  cobra.ExactArgs(1),
+		Long:  "显示日志，指定 -l/--namespace 时依次显示所有匹配应用的日志，--follow 只能跟踪一个应用",
+		Args:  cobra.MaximumNArgs(1),
 		Run:   runLogs,
 	}
 
@@ -116,7 +214,7 @@ func init() {
 	var describeCmd = &cobra.Command{
 		Use:   "describe <name|id>",
 		Short: "显示进程详细信息",
-		Args:  cobra.ExactArgs(1),
+		Args:  cobra.MaximumNArgs(1),
 		Run:   runDescribe,
 	}
 
@@ -129,8 +227,9 @@ func init() {
 
 	// flush 命令
 	var flushCmd = &cobra.Command{
-		Use:   "flush",
-		Short: "清空所有日志文件",
+		Use:   "flush [name|id]",
+		Short: "清空日志文件，未指定应用和选择器时清空所有日志",
+		Args:  cobra.MaximumNArgs(1),
 		Run:   runFlush,
 	}
 
@@ -184,14 +283,14 @@ func init() {
 	var watchEnableCmd = &cobra.Command{
 		Use:   "enable <name|id>",
 		Short: "启用文件监控",
-		Args:  cobra.ExactArgs(1),
+		Args:  cobra.MaximumNArgs(1),
 		Run:   runWatchEnable,
 	}
 
 	var watchDisableCmd = &cobra.Command{
 		Use:   "disable <name|id>",
 		Short: "禁用文件监控",
-		Args:  cobra.ExactArgs(1),
+		Args:  cobra.MaximumNArgs(1),
 		Run:   runWatchDisable,
 	}
 
@@ -203,11 +302,20 @@ func init() {
 	}
 
 	// 添加子命令
+	// 命名空间和标签选择器
+	for _, c := range []*cobra.Command{
+		stopCmd, restartCmd, deleteCmd, pauseCmd, resumeCmd, signalCmd, listCmd, jobsCmd, monitCmd,
+		logsCmd, describeCmd, flushCmd, runCmd, setCmd, watchEnableCmd, watchDisableCmd,
+	} {
+		c.Flags().StringP("selector", "l", "", "标签选择器 (如 team=payments,tier!=web)")
+		c.Flags().StringP("namespace", "", "", "命名空间")
+	}
+
 	configCmd.AddCommand(configGenerateCmd, configExportCmd)
 	watchCmd.AddCommand(watchEnableCmd, watchDisableCmd)
 
 	rootCmd.AddCommand(
-		daemonCmd, startCmd, stopCmd, restartCmd, deleteCmd, listCmd,
+		daemonCmd, startCmd, stopCmd, restartCmd, deleteCmd, pauseCmd, resumeCmd, signalCmd, setCmd, runCmd, jobsCmd, listCmd,
 		logsCmd, describeCmd, monitCmd, flushCmd,
 		configCmd, startupCmd, saveCmd, resurrectCmd, watchCmd, stopDaemonCmd,
 	)
@@ -223,25 +331,101 @@ func Execute() {
 
 // runStart 启动命令处理
 func runStart(cmd *cobra.Command, args []string) {
-	script := args[0]
+	shell, _ := cmd.Flags().GetString("shell")
+	shell = strings.TrimSpace(shell)
+
+	// -- 之后的参数原样传给脚本
+	var passArgs []string
+	if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+		passArgs = args[dash:]
+		args = args[:dash]
+	}
+
+	script := ""
+	switch {
+	case shell != "" && len(args) > 0:
+		fmt.Println("错误: --shell 不能与脚本同时指定")
+		os.Exit(1)
+	case shell == "" && len(args) == 0:
+		fmt.Println("错误: 请指定要启动的脚本、配置文件或 --shell 命令")
+		os.Exit(1)
+	case len(args) > 1:
+		fmt.Printf("错误: 多余的参数 %v，传给脚本的参数请放在 -- 之后\n", args[1:])
+		os.Exit(1)
+	case shell == "":
+		script = args[0]
+	}
 
 	// 检查是否是配置文件
-	if strings.HasSuffix(script, ".json") || strings.HasSuffix(script, ".yml") || strings.HasSuffix(script, ".yaml") {
+	if script != "" && isConfigFile(script) {
 		config, err := LoadConfig(script)
 		if err != nil {
 			fmt.Printf("错误: %v\n", err)
 			os.Exit(1)
 		}
 
-		for _, appConfig := range config.Apps {
+		env, profile, err := parseEnvFlag(cmd)
+		if err != nil {
+			fmt.Printf("错误: %v\n", err)
+			os.Exit(1)
+		}
+		if len(env) > 0 {
+			fmt.Printf("错误: 使用配置文件时 --env 只能指定环境配置名称，环境变量请写入配置文件的 env\n")
+			os.Exit(1)
+		}
+		if profile != "" {
+			defined := false
+			for i := range config.Apps {
+				// 未定义该环境配置的应用只使用基础 env
+				if hasEnvProfile(config.Apps[i].Envs, profile) {
+					config.Apps[i].EnvProfile = profile
+					defined = true
+				}
+			}
+			if !defined {
+				fmt.Printf("错误: 配置文件中未定义环境配置: %s\n", profile)
+				os.Exit(1)
+			}
+		}
+
+		waitDeps, _ := cmd.Flags().GetString("wait-deps")
+		depsTimeout, _ := cmd.Flags().GetDuration("deps-timeout")
+		if waitDeps != "none" && waitDeps != "online" && waitDeps != "ready" {
+			fmt.Printf("错误: 不支持的依赖等待方式: %s\n", waitDeps)
+			os.Exit(1)
+		}
+
+		// 按依赖顺序启动，依赖启动失败的应用将被跳过
+		apps, _ := sortAppsByDependency(config.Apps)
+		failed := make(map[string]bool)
+		for _, appConfig := range apps {
+			applyGroupFlags(cmd, &appConfig)
+
+			// 重复执行 start 时已存在的应用不再启动，仍在运行的视为依赖已满足
+			if p, err := fetchProcess(appConfig.Name); err == nil && p.Status != StatusStopped {
+				fmt.Printf("'%s' 已存在 (状态: %s)，跳过\n", appConfig.Name, p.Status)
+				if p.Status == StatusErrored || p.Status == StatusBuildFailed {
+					failed[appConfig.Name] = true
+				}
+				continue
+			}
+
+			if err := waitForDependencies(appConfig, failed, waitDeps, depsTimeout); err != nil {
+				fmt.Printf("跳过 '%s': %v\n", appConfig.Name, err)
+				failed[appConfig.Name] = true
+				continue
+			}
+
 			configJSON, _ := json.Marshal(appConfig)
-			response, err := pm.sendCommand("START", string(configJSON))
+			response, err := pm.sendCommandWithTimeout(startCommandTimeout(appConfig), "START", string(configJSON))
 			if err != nil {
 				fmt.Printf("启动 '%s' 失败: %v\n", appConfig.Name, err)
+				failed[appConfig.Name] = true
 			} else if strings.HasPrefix(response, "SUCCESS:") {
 				fmt.Println("✓ " + strings.TrimPrefix(response, "SUCCESS: "))
 			} else {
 				fmt.Printf("启动 '%s' 失败: %s\n", appConfig.Name, strings.TrimPrefix(response, "ERROR: "))
+				failed[appConfig.Name] = true
 			}
 		}
 		return
@@ -250,14 +434,28 @@ func runStart(cmd *cobra.Command, args []string) {
 	// 解析参数
 	name, _ := cmd.Flags().GetString("name")
 	if name == "" {
-		// 从脚本路径提取名称
-		parts := strings.Split(script, "/")
+		// 从脚本路径或 shell 命令的第一个词提取名称
+		source := script
+		if shell != "" {
+			source = strings.Fields(shell)[0]
+		}
+		parts := strings.Split(source, "/")
 		name = strings.TrimSuffix(parts[len(parts)-1], filepath.Ext(parts[len(parts)-1]))
 	}
 
 	args_list, _ := cmd.Flags().GetStringArray("args")
+	args_list = append(args_list, passArgs...)
 	cwd, _ := cmd.Flags().GetString("cwd")
-	env, _ := cmd.Flags().GetStringToString("env")
+	env, profile, err := parseEnvFlag(cmd)
+	if err != nil {
+		fmt.Printf("错误: %v\n", err)
+		os.Exit(1)
+	}
+	if profile != "" {
+		fmt.Printf("错误: 环境配置 '%s' 仅适用于配置文件\n", profile)
+		os.Exit(1)
+	}
+	envFiles, _ := cmd.Flags().GetStringArray("env-file")
 	instances, _ := cmd.Flags().GetInt("instances")
 	execMode, _ := cmd.Flags().GetString("exec-mode")
 	watch, _ := cmd.Flags().GetBool("watch")
@@ -266,13 +464,47 @@ func runStart(cmd *cobra.Command, args []string) {
 	errorFile, _ := cmd.Flags().GetString("error")
 	maxRestarts, _ := cmd.Flags().GetInt("max-restarts")
 	minUptime, _ := cmd.Flags().GetString("min-uptime")
+	port, _ := cmd.Flags().GetInt("port")
+	interpreter, _ := cmd.Flags().GetString("interpreter")
+	interpreterArgs, _ := cmd.Flags().GetStringArray("interpreter-args")
+	runUser, _ := cmd.Flags().GetString("user")
+	runGroup, _ := cmd.Flags().GetString("group")
+
+	// 相对路径按调用方的当前目录解析，守护进程的工作目录与调用方不同
+	callerDir, _ := os.Getwd()
+	if cwd == "" {
+		cwd = callerDir
+	}
+	cwd = resolvePath(callerDir, cwd)
+	script = resolveScriptPath(callerDir, script)
+	for i, file := range envFiles {
+		envFiles[i] = resolvePath(callerDir, file)
+	}
+	if logFile != "" {
+		logFile = resolvePath(callerDir, logFile)
+	}
+	if errorFile != "" {
+		errorFile = resolvePath(callerDir, errorFile)
+	}
+
+	// 传递调用方的部分环境变量，-e 指定的优先
+	for _, key := range clientEnvVars {
+		if _, exists := env[key]; exists {
+			continue
+		}
+		if value, exists := os.LookupEnv(key); exists {
+			env[key] = value
+		}
+	}
 
 	config := AppConfig{
 		Name:        name,
 		Script:      script,
+		Command:     shell,
 		Args:        args_list,
 		Cwd:         cwd,
 		Env:         env,
+		EnvFile:     envFiles,
 		Instances:   instances,
 		ExecMode:    execMode,
 		Watch:       watch,
@@ -281,10 +513,18 @@ func runStart(cmd *cobra.Command, args []string) {
 		ErrorFile:   errorFile,
 		MaxRestarts: maxRestarts,
 		MinUptime:   minUptime,
+		Port:        port,
+
+		Interpreter:     interpreter,
+		InterpreterArgs: interpreterArgs,
+
+		User:  runUser,
+		Group: runGroup,
 	}
+	applyGroupFlags(cmd, &config)
 
 	configJSON, _ := json.Marshal(config)
-	response, err := pm.sendCommand("START", string(configJSON))
+	response, err := pm.sendCommandWithTimeout(startCommandTimeout(config), "START", string(configJSON))
 	if err != nil {
 		fmt.Printf("错误: %v\n", err)
 		os.Exit(1)
@@ -298,94 +538,636 @@ func runStart(cmd *cobra.Command, args []string) {
 	}
 }
 
+// applyGroupFlags 将命令行指定的命名空间和标签作为应用的默认值
+func applyGroupFlags(cmd *cobra.Command, app *AppConfig) {
+	namespace, _ := cmd.Flags().GetString("namespace")
+	labels, _ := cmd.Flags().GetStringToString("label")
+
+	if app.Namespace == "" {
+		app.Namespace = namespace
+	}
+	for key, value := range labels {
+		if app.Labels == nil {
+			app.Labels = make(map[string]string)
+		}
+		if _, exists := app.Labels[key]; !exists {
+			app.Labels[key] = value
+		}
+	}
+}
+
+// parseEnvFlag 解析 --env 参数，key=value 为环境变量，不含 = 的为环境配置名称
+// 一个参数可以用逗号分隔多个变量，如 -e A=1,B=2
+func parseEnvFlag(cmd *cobra.Command) (map[string]string, string, error) {
+	values, _ := cmd.Flags().GetStringArray("env")
+
+	env := make(map[string]string)
+	profile := ""
+	for _, value := range values {
+		if strings.Contains(value, "=") {
+			for _, pair := range splitEnvPairs(value) {
+				key, val, _ := strings.Cut(pair, "=")
+				env[key] = val
+			}
+			continue
+		}
+		if profile != "" && profile != value {
+			return nil, "", fmt.Errorf("只能指定一个环境配置: %s, %s", profile, value)
+		}
+		profile = value
+	}
+	return env, profile, nil
+}
+
+// splitEnvPairs 按逗号拆分多个 key=value，逗号后不是 key=value 时视为值的一部分，如 A=x,y 中的 x,y
+func splitEnvPairs(value string) []string {
+	var pairs []string
+	for _, part := range strings.Split(value, ",") {
+		if key, _, ok := strings.Cut(part, "="); (ok && isVarName(key)) || len(pairs) == 0 {
+			pairs = append(pairs, part)
+			continue
+		}
+		pairs[len(pairs)-1] += "," + part
+	}
+	return pairs
+}
+
+// isConfigFile 根据扩展名判断是否是配置文件
+func isConfigFile(path string) bool {
+	return strings.HasSuffix(path, ".json") || strings.HasSuffix(path, ".yml") || strings.HasSuffix(path, ".yaml")
+}
+
+// waitForDependencies 等待应用的依赖就绪
+// mode 为 none 时不等待，online 等待依赖在线，ready 还要求依赖运行超过最小运行时间
+func waitForDependencies(app AppConfig, failed map[string]bool, mode string, timeout time.Duration) error {
+	for _, dep := range app.DependsOn {
+		if failed[dep] {
+			return fmt.Errorf("依赖 '%s' 启动失败", dep)
+		}
+		if mode == "none" {
+			continue
+		}
+
+		deadline := time.Now().Add(timeout)
+		for {
+			p, err := fetchProcess(dep)
+			if err == nil && dependencyReady(p, mode) {
+				break
+			}
+			if time.Now().After(deadline) {
+				return fmt.Errorf("等待依赖 '%s' 就绪超时 (%s)", dep, timeout)
+			}
+			time.Sleep(500 * time.Millisecond)
+		}
+	}
+	return nil
+}
+
+// dependencyReady 判断依赖是否满足启动条件，任务类型的依赖需要成功运行结束
+func dependencyReady(p *Process, mode string) bool {
+	if p.Type == AppTypeJob {
+		return p.Status == StatusOneTime
+	}
+	if p.Status != StatusOnline {
+		return false
+	}
+	if mode == "ready" {
+		return p.Uptime >= p.MinUptime
+	}
+	return true
+}
+
 // runStop 停止命令处理
 func runStop(cmd *cobra.Command, args []string) {
-	nameOrID := args[0]
-	response, err := pm.sendCommand("STOP", nameOrID)
-	if err != nil {
-		fmt.Printf("错误: %v\n", err)
-		os.Exit(1)
+	nameOrID := targetArg(args)
+
+	// 按依赖关系逆序停止配置文件中的应用
+	if isConfigFile(nameOrID) {
+		config, err := LoadConfig(nameOrID)
+		if err != nil {
+			fmt.Printf("错误: %v\n", err)
+			os.Exit(1)
+		}
+
+		apps, _ := sortAppsByDependency(config.Apps)
+		for i := len(apps) - 1; i >= 0; i-- {
+			sendBulkCommand(cmd, "STOP", apps[i].Name)
+		}
+		return
 	}
 
-	if strings.HasPrefix(response, "SUCCESS:") {
-		fmt.Println("✓ " + strings.TrimPrefix(response, "SUCCESS: "))
-	} else {
-		fmt.Printf("错误: %s\n", strings.TrimPrefix(response, "ERROR: "))
+	if !sendBulkCommand(cmd, "STOP", nameOrID) {
 		os.Exit(1)
 	}
 }
 
 // runRestart 重启命令处理
 func runRestart(cmd *cobra.Command, args []string) {
-	nameOrID := args[0]
-	response, err := pm.sendCommand("RESTART", nameOrID)
+	updateEnv, _ := cmd.Flags().GetBool("update-env")
+	env, profile, err := parseEnvFlag(cmd)
 	if err != nil {
 		fmt.Printf("错误: %v\n", err)
 		os.Exit(1)
 	}
+	if (profile != "" || len(env) > 0) && !updateEnv {
+		fmt.Println("错误: -e/--env 需要同时指定 --update-env")
+		os.Exit(1)
+	}
 
-	if strings.HasPrefix(response, "SUCCESS:") {
-		fmt.Println("✓ " + strings.TrimPrefix(response, "SUCCESS: "))
-	} else {
-		fmt.Printf("错误: %s\n", strings.TrimPrefix(response, "ERROR: "))
+	// 附加参数依次为环境配置、-e 指定的变量和调用方的环境变量
+	var extra []string
+	if updateEnv {
+		callerEnv := make(map[string]string)
+		for _, key := range clientEnvVars {
+			if value, exists := os.LookupEnv(key); exists {
+				callerEnv[key] = value
+			}
+		}
+		envJSON, _ := json.Marshal(env)
+		callerEnvJSON, _ := json.Marshal(callerEnv)
+		extra = []string{profile, string(envJSON), string(callerEnvJSON)}
+	}
+	if !sendBulkCommand(cmd, "RESTART", targetArg(args), extra...) {
 		os.Exit(1)
 	}
 }
 
 // runDelete 删除命令处理
 func runDelete(cmd *cobra.Command, args []string) {
-	nameOrID := args[0]
-	response, err := pm.sendCommand("DELETE", nameOrID)
+	if !sendBulkCommand(cmd, "DELETE", targetArg(args)) {
+		os.Exit(1)
+	}
+}
+
+// runPause 暂停命令处理
+func runPause(cmd *cobra.Command, args []string) {
+	if !sendBulkCommand(cmd, "PAUSE", targetArg(args)) {
+		os.Exit(1)
+	}
+}
+
+// runResume 恢复命令处理
+func runResume(cmd *cobra.Command, args []string) {
+	if !sendBulkCommand(cmd, "RESUME", targetArg(args)) {
+		os.Exit(1)
+	}
+}
+
+// runSignal 发送信号命令处理
+func runSignal(cmd *cobra.Command, args []string) {
+	if _, _, err := parseSignal(args[0]); err != nil {
+		fmt.Printf("错误: %v\n", err)
+		os.Exit(1)
+	}
+
+	if !sendBulkCommand(cmd, "SIGNAL", targetArg(args[1:]), args[0]) {
+		os.Exit(1)
+	}
+}
+
+// runSet 修改配置命令处理
+func runSet(cmd *cobra.Command, args []string) {
+	unset, _ := cmd.Flags().GetStringArray("unset")
+
+	// 指定了选择器时省略应用名称，所有参数都是修改项
+	nameOrID, assignmentArgs := "", args
+	if !hasSelector(cmd) {
+		nameOrID, assignmentArgs = args[0], args[1:]
+	}
+
+	// 相对路径按调用方的当前目录解析，与 start 一致
+	callerDir, _ := os.Getwd()
+	var assignments []string
+	for _, assignment := range assignmentArgs {
+		key, value, ok := strings.Cut(assignment, "=")
+		if !ok {
+			fmt.Printf("错误: 无效的修改: %s (应为 key=value)\n", assignment)
+			os.Exit(1)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			fmt.Printf("错误: %s 的取值不能包含换行\n", key)
+			os.Exit(1)
+		}
+		switch key {
+		case "cwd", "log_file", "error_file":
+			if value != "" {
+				value = resolvePath(callerDir, value)
+			}
+		case "script":
+			value = resolveScriptPath(callerDir, value)
+		}
+		assignments = append(assignments, key+"="+value)
+	}
+	for _, key := range unset {
+		if strings.Contains(key, "=") || (!strings.HasPrefix(key, "env.") && !strings.HasPrefix(key, "labels.")) {
+			fmt.Printf("错误: --unset 只能删除 env.<KEY> 或 labels.<KEY>: %s\n", key)
+			os.Exit(1)
+		}
+		assignments = append(assignments, key)
+	}
+	if len(assignments) == 0 {
+		fmt.Println("错误: 请指定要修改的字段，如 env.LOG_LEVEL=debug")
+		os.Exit(1)
+	}
+
+	targets, err := commandTargets(cmd, nameOrID)
 	if err != nil {
 		fmt.Printf("错误: %v\n", err)
 		os.Exit(1)
 	}
 
-	if strings.HasPrefix(response, "SUCCESS:") {
-		fmt.Println("✓ " + strings.TrimPrefix(response, "SUCCESS: "))
-	} else {
-		fmt.Printf("错误: %s\n", strings.TrimPrefix(response, "ERROR: "))
+	ok := true
+	for _, target := range targets {
+		response, err := pm.sendCommand("SET", append([]string{target.ref}, assignments...)...)
+		if err != nil {
+			fmt.Printf("错误: %v\n", err)
+			os.Exit(1)
+		}
+
+		if strings.HasPrefix(response, "SUCCESS:") {
+			fmt.Println("✓ " + strings.TrimPrefix(response, "SUCCESS: "))
+		} else {
+			fmt.Printf("错误: %s\n", strings.TrimPrefix(response, "ERROR: "))
+			ok = false
+		}
+	}
+	if !ok {
 		os.Exit(1)
 	}
 }
 
-// runList 列表命令处理
-func runList(cmd *cobra.Command, args []string) {
-	response, err := pm.sendCommand("LIST")
+// targetArg 返回可选的目标参数
+func targetArg(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	return args[0]
+}
+
+// filterBySelector 按命令行的命名空间和标签选择器过滤进程
+func filterBySelector(cmd *cobra.Command, processes []*Process) ([]*Process, error) {
+	labelSelector, _ := cmd.Flags().GetString("selector")
+	namespace, _ := cmd.Flags().GetString("namespace")
+
+	reqs, err := parseLabelSelector(labelSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]*Process, 0, len(processes))
+	for _, p := range processes {
+		if p.matchesSelector(namespace, reqs) {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered, nil
+}
+
+// commandTarget 单个应用命令的操作目标，ref 为发送给守护进程的名称或 ID
+type commandTarget struct {
+	ref     string
+	name    string
+	process *Process // 按选择器匹配时为匹配到的进程，否则为 nil
+}
+
+// hasSelector 判断命令行是否指定了命名空间或标签选择器
+func hasSelector(cmd *cobra.Command) bool {
+	labelSelector, _ := cmd.Flags().GetString("selector")
+	namespace, _ := cmd.Flags().GetString("namespace")
+	return labelSelector != "" || namespace != ""
+}
+
+// commandTargets 返回只接受单个应用的命令 (logs、describe 等) 要操作的应用
+// 指定了 -l/--namespace 时按 ID 返回所有匹配的应用，同时指定了名称或 ID 时只保留该应用
+func commandTargets(cmd *cobra.Command, nameOrID string) ([]commandTarget, error) {
+	if !hasSelector(cmd) {
+		if nameOrID == "" {
+			return nil, fmt.Errorf("请指定应用名称、ID 或选择器 (-l/--namespace)")
+		}
+		return []commandTarget{{ref: nameOrID, name: nameOrID}}, nil
+	}
+
+	processes, err := fetchProcesses()
+	if err != nil {
+		return nil, err
+	}
+	processes, err = filterBySelector(cmd, processes)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(processes, func(i, j int) bool { return processes[i].ID < processes[j].ID })
+
+	id, idErr := strconv.Atoi(nameOrID)
+	var targets []commandTarget
+	for _, p := range processes {
+		if nameOrID != "" && p.Name != nameOrID && (idErr != nil || p.ID != id) {
+			continue
+		}
+		targets = append(targets, commandTarget{ref: strconv.Itoa(p.ID), name: p.Name, process: p})
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("没有匹配选择器的应用")
+	}
+	return targets, nil
+}
+
+// sendBulkCommand 发送批量命令并逐行输出每个目标的结果，全部成功时返回 true
+func sendBulkCommand(cmd *cobra.Command, command, selector string, extra ...string) bool {
+	parallel, _ := cmd.Flags().GetInt("parallel")
+	labelSelector, _ := cmd.Flags().GetString("selector")
+	namespace, _ := cmd.Flags().GetString("namespace")
+
+	if selector == "" && labelSelector == "" && namespace == "" {
+		fmt.Println("错误: 请指定目标进程或选择器 (-l/--namespace)")
+		return false
+	}
+
+	args := append([]string{selector, strconv.Itoa(parallel), labelSelector, namespace}, extra...)
+	response, err := pm.sendCommandWithTimeout(bulkCommandTimeout, command, args...)
+	if err != nil {
+		fmt.Printf("错误: %v\n", err)
+		return false
+	}
+
+	ok := true
+	for _, line := range strings.Split(response, "\n") {
+		if strings.HasPrefix(line, "SUCCESS:") {
+			fmt.Println("✓ " + strings.TrimPrefix(line, "SUCCESS: "))
+		} else {
+			fmt.Printf("错误: %s\n", strings.TrimPrefix(line, "ERROR: "))
+			ok = false
+		}
+	}
+	return ok
+}
+
+// runRun 运行任务命令处理
+func runRun(cmd *cobra.Command, args []string) {
+	wait, _ := cmd.Flags().GetBool("wait")
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+
+	targets, err := commandTargets(cmd, targetArg(args))
 	if err != nil {
 		fmt.Printf("错误: %v\n", err)
 		os.Exit(1)
 	}
 
-	if strings.HasPrefix(response, "ERROR:") {
-		fmt.Printf("错误: %s\n", strings.TrimPrefix(response, "ERROR: "))
+	// 按选择器匹配时只运行其中的任务
+	if hasSelector(cmd) {
+		jobs := targets[:0]
+		for _, target := range targets {
+			if target.process.Type == AppTypeJob {
+				jobs = append(jobs, target)
+			}
+		}
+		if len(jobs) == 0 {
+			fmt.Println("错误: 没有匹配选择器的任务")
+			os.Exit(1)
+		}
+		targets = jobs
+	}
+
+	// 先触发所有任务，再依次等待运行结果
+	ok := true
+	lastRunIDs := make(map[string]int)
+	var triggered []commandTarget
+	for _, target := range targets {
+		// 记录触发前的运行次数，用于识别本次运行结果
+		if wait {
+			if p, err := fetchProcess(target.ref); err == nil {
+				lastRunIDs[target.ref] = p.RunCount
+			}
+		}
+
+		// 守护进程在响应前执行 pre_start 钩子
+		response, err := pm.sendCommandWithTimeout(bulkCommandTimeout, "RUN", target.ref)
+		if err != nil {
+			fmt.Printf("错误: %v\n", err)
+			os.Exit(1)
+		}
+
+		if strings.HasPrefix(response, "SUCCESS:") {
+			fmt.Println("✓ " + strings.TrimPrefix(response, "SUCCESS: "))
+			triggered = append(triggered, target)
+		} else {
+			fmt.Printf("错误: %s\n", strings.TrimPrefix(response, "ERROR: "))
+			ok = false
+		}
+	}
+
+	if wait {
+		startWait := time.Now()
+		for _, target := range triggered {
+			if !waitForJobRun(target.ref, lastRunIDs[target.ref], startWait, timeout) {
+				ok = false
+			}
+		}
+	}
+	if !ok {
+		os.Exit(1)
+	}
+}
+
+// waitForJobRun 等待任务编号大于 lastRunID 的运行结束并输出结果，运行成功时返回 true
+func waitForJobRun(nameOrID string, lastRunID int, startWait time.Time, timeout time.Duration) bool {
+	for {
+		time.Sleep(500 * time.Millisecond)
+
+		p, err := fetchProcess(nameOrID)
+		if err != nil {
+			fmt.Printf("错误: %v\n", err)
+			return false
+		}
+
+		if run := findRunAfter(p, lastRunID); run != nil {
+			fmt.Printf("任务 '%s' 运行结束: 退出码 %d，耗时 %s\n", p.Name, run.ExitCode, formatDuration(run.Duration))
+			fmt.Printf("  日志文件: %s\n", run.LogFile)
+			fmt.Printf("  错误日志: %s\n", run.ErrorFile)
+			return run.ExitCode == 0
+		}
+
+		if timeout > 0 && time.Since(startWait) > timeout {
+			fmt.Printf("错误: 等待任务 '%s' 超时 (%s)\n", p.Name, timeout)
+			return false
+		}
+	}
+}
+
+// findRunAfter 查找编号大于 runID 的第一次已结束运行
+func findRunAfter(p *Process, runID int) *JobRun {
+	for _, run := range p.Runs {
+		if run.ID > runID {
+			return run
+		}
+	}
+	return nil
+}
+
+// runJobs 任务列表命令处理
+func runJobs(cmd *cobra.Command, args []string) {
+	if len(args) > 0 {
+		runJobHistory(args[0])
+		return
+	}
+
+	processes, err := fetchProcesses()
+	if err == nil {
+		processes, err = filterBySelector(cmd, processes)
+	}
+	if err != nil {
+		fmt.Printf("错误: %v\n", err)
 		os.Exit(1)
 	}
 
-	// 解析进程列表
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\t名称\t计划\t状态\t上次运行\t退出码\t耗时\t下次运行")
+	fmt.Fprintln(w, "--\t----\t----\t----\t--------\t------\t----\t--------")
+
+	count := 0
+	for _, p := range processes {
+		if p.Type != AppTypeJob {
+			continue
+		}
+		count++
+
+		schedule := p.Schedule
+		if schedule == "" {
+			schedule = "-"
+		}
+		lastRun, exitCode, duration := "-", "-", "-"
+		if run := p.lastRun(); run != nil {
+			lastRun = run.StartTime.Format("2006-01-02 15:04:05")
+			exitCode = strconv.Itoa(run.ExitCode)
+			duration = formatDuration(run.Duration)
+		}
+		nextRun := "-"
+		if !p.NextRun.IsZero() {
+			nextRun = p.NextRun.Format("2006-01-02 15:04:05")
+		}
+
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			p.ID, p.Name, schedule, p.Status, lastRun, exitCode, duration, nextRun)
+	}
+
+	if count == 0 {
+		fmt.Println("没有任务")
+		return
+	}
+
+	w.Flush()
+}
+
+// runJobHistory 显示单个任务保留的运行记录
+func runJobHistory(nameOrID string) {
+	p, err := fetchProcess(nameOrID)
+	if err != nil {
+		fmt.Printf("错误: %v\n", err)
+		os.Exit(1)
+	}
+
+	if len(p.Runs) == 0 {
+		fmt.Printf("任务 '%s' 还没有运行记录\n", p.Name)
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "编号\t触发\t开始时间\t耗时\t退出码\t日志")
+	fmt.Fprintln(w, "----\t----\t--------\t----\t------\t----")
+
+	for i := len(p.Runs) - 1; i >= 0; i-- {
+		run := p.Runs[i]
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%d\t%s\n",
+			run.ID, run.Trigger, run.StartTime.Format("2006-01-02 15:04:05"),
+			formatDuration(run.Duration), run.ExitCode, run.LogFile)
+	}
+
+	w.Flush()
+}
+
+// fetchProcesses 从守护进程获取进程列表
+func fetchProcesses() ([]*Process, error) {
+	response, err := pm.sendCommand("LIST")
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.HasPrefix(response, "ERROR:") {
+		return nil, fmt.Errorf("%s", strings.TrimPrefix(response, "ERROR: "))
+	}
+
 	var processes []*Process
 	err = json.Unmarshal([]byte(response), &processes)
 	if err != nil {
-		fmt.Printf("解析进程列表失败: %v\n", err)
+		return nil, fmt.Errorf("解析进程列表失败: %v", err)
+	}
+
+	return processes, nil
+}
+
+// fetchProcess 从守护进程获取单个进程（通过名称或ID）
+func fetchProcess(nameOrID string) (*Process, error) {
+	processes, err := fetchProcesses()
+	if err != nil {
+		return nil, err
+	}
+
+	id, idErr := strconv.Atoi(nameOrID)
+	for _, p := range processes {
+		if (idErr == nil && p.ID == id) || p.Name == nameOrID {
+			return p, nil
+		}
+	}
+
+	return nil, fmt.Errorf("未找到进程: %s", nameOrID)
+}
+
+// runList 列表命令处理
+func runList(cmd *cobra.Command, args []string) {
+	processes, err := fetchProcesses()
+	if err == nil {
+		processes, err = filterBySelector(cmd, processes)
+	}
+	if err != nil {
+		fmt.Printf("错误: %v\n", err)
 		os.Exit(1)
 	}
 
+	// 环境变量中的密钥以引用形式输出，敏感变量的明文值替换为掩码
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		if processes == nil {
+			processes = []*Process{}
+		}
+		for _, p := range processes {
+			maskEnv(p.Env)
+			for _, env := range p.Envs {
+				maskEnv(env)
+			}
+		}
+		data, _ := json.MarshalIndent(processes, "", "  ")
+		fmt.Println(string(data))
+		return
+	}
+
 	if len(processes) == 0 {
 		fmt.Println("没有运行的进程")
 		return
 	}
 
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "ID\t名称\t状态\tPID\tCPU\t内存\t运行时间\t重启次数")
-	fmt.Fprintln(w, "--\t----\t----\t---\t---\t----\t--------\t--------")
+	fmt.Fprintln(w, "ID\t名称\t命名空间\t状态\t健康\tPID\t端口\tCPU\t内存\t运行时间\t重启次数")
+	fmt.Fprintln(w, "--\t----\t--------\t----\t----\t---\t----\t---\t----\t--------\t--------")
 
 	for _, p := range processes {
 		uptime := formatDuration(p.Uptime)
 		memory := formatBytes(p.MemoryUsage)
 		cpu := fmt.Sprintf("%.1f%%", p.CPUUsage)
+		health := string(p.Health)
+		if health == "" {
+			health = "-"
+		}
 
-		fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%s\t%s\t%s\t%d\n",
-			p.ID, p.Name, p.Status, p.PID, cpu, memory, uptime, p.Restarts)
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%d\t%s\t%s\t%s\t%s\t%d\n",
+			p.ID, p.Name, p.namespace(), p.Status, health, p.PID, formatPorts(p.Ports), cpu, memory, uptime, p.Restarts)
 	}
 
 	w.Flush()
@@ -393,11 +1175,22 @@ func runList(cmd *cobra.Command, args []string) {
 
 // runLogs 日志命令处理
 func runLogs(cmd *cobra.Command, args []string) {
-	nameOrID := args[0]
 	lines, _ := cmd.Flags().GetInt("lines")
 	follow, _ := cmd.Flags().GetBool("follow")
 	showError, _ := cmd.Flags().GetBool("error")
 
+	targets, err := commandTargets(cmd, targetArg(args))
+	if err != nil {
+		fmt.Printf("错误: %v\n", err)
+		os.Exit(1)
+	}
+	// 跟踪模式一次只能输出一个日志文件
+	if follow && len(targets) > 1 {
+		fmt.Printf("错误: --follow 只能跟踪一个应用，选择器匹配了 %d 个应用\n", len(targets))
+		os.Exit(1)
+	}
+	nameOrID := targets[0].ref
+
 	if follow {
 		// 对于follow模式，我们需要直接处理，因为需要实时输出
 		// 通过守护进程处理follow模式
@@ -444,41 +1237,78 @@ func runLogs(cmd *cobra.Command, args []string) {
 			os.Exit(1)
 		}
 	} else {
-		// 非follow模式，使用原来的方式
-		var err error
-		if showError {
-			err = pm.GetErrorLogs(nameOrID, lines, false)
-		} else {
-			err = pm.GetLogs(nameOrID, lines, false)
-		}
+		// 非follow模式，使用原来的方式，多个应用依次显示
+		for i, target := range targets {
+			if len(targets) > 1 {
+				if i > 0 {
+					fmt.Println()
+				}
+				fmt.Printf("==> %s (ID: %s) <==\n", target.name, target.ref)
+			}
 
-		if err != nil {
-			fmt.Printf("错误: %v\n", err)
-			os.Exit(1)
+			var err error
+			if showError {
+				err = pm.GetErrorLogs(target.ref, lines, false)
+			} else {
+				err = pm.GetLogs(target.ref, lines, false)
+			}
+
+			if err != nil {
+				fmt.Printf("错误: %v\n", err)
+				os.Exit(1)
+			}
 		}
 	}
 }
 
 // runDescribe 详情命令处理
 func runDescribe(cmd *cobra.Command, args []string) {
-	nameOrID := args[0]
-	process := pm.findProcess(nameOrID)
-	if process == nil {
-		fmt.Printf("未找到进程: %s\n", nameOrID)
+	targets, err := commandTargets(cmd, targetArg(args))
+	if err != nil {
+		fmt.Printf("错误: %v\n", err)
 		os.Exit(1)
 	}
 
+	ports := newPortSnapshot()
+	for i, target := range targets {
+		process := pm.findProcess(target.ref)
+		if process == nil {
+			fmt.Printf("未找到进程: %s\n", target.name)
+			os.Exit(1)
+		}
+		if i > 0 {
+			fmt.Println()
+		}
+		describeProcess(process, ports)
+	}
+}
+
+// describeProcess 输出进程详细信息，监听端口从 ports 中查找
+func describeProcess(process *Process, ports *portSnapshot) {
 	// 更新统计信息
-	pm.updateProcessStats(process)
+	pm.updateProcessStats(process, ports)
 
 	fmt.Printf("进程详情:\n")
 	fmt.Printf("  ID: %d\n", process.ID)
 	fmt.Printf("  名称: %s\n", process.Name)
-	fmt.Printf("  脚本: %s\n", process.Script)
+	if process.Command != "" {
+		fmt.Printf("  命令: %s\n", process.Command)
+	} else {
+		fmt.Printf("  脚本: %s\n", process.Script)
+	}
 	fmt.Printf("  参数: %v\n", process.Args)
 	fmt.Printf("  工作目录: %s\n", process.Cwd)
 	fmt.Printf("  状态: %s\n", process.Status)
 	fmt.Printf("  PID: %d\n", process.PID)
+	if port := process.declaredPort(); port > 0 {
+		fmt.Printf("  声明端口: %d\n", port)
+	}
+	if len(process.Ports) > 0 {
+		fmt.Println("  监听端口:")
+		for _, lp := range process.Ports {
+			fmt.Printf("    %s %s (PID: %d)\n", lp.Protocol, net.JoinHostPort(lp.Address, strconv.Itoa(lp.Port)), lp.PID)
+		}
+	}
 	fmt.Printf("  CPU 使用率: %.1f%%\n", process.CPUUsage)
 	fmt.Printf("  内存使用: %s\n", formatBytes(process.MemoryUsage))
 	fmt.Printf("  运行时间: %s\n", formatDuration(process.Uptime))
@@ -486,14 +1316,107 @@ func runDescribe(cmd *cobra.Command, args []string) {
 	fmt.Printf("  最大重启次数: %d\n", process.MaxRestarts)
 	fmt.Printf("  启动时间: %s\n", process.StartTime.Format("2006-01-02 15:04:05"))
 	fmt.Printf("  执行模式: %s\n", process.ExecMode)
+	if process.User != "" {
+		fmt.Printf("  运行用户: %s\n", process.User)
+		if process.Group != "" {
+			fmt.Printf("  用户组: %s\n", process.Group)
+		}
+	}
+	if process.CgroupPath != "" {
+		fmt.Printf("  cgroup: %s\n", process.CgroupPath)
+	}
+	if process.ExitReason != "" {
+		fmt.Printf("  上次异常退出原因: %s\n", process.ExitReason)
+	}
+	if process.HealthCheck != nil {
+		health := string(process.Health)
+		if health == "" {
+			health = "-"
+		}
+		fmt.Printf("  健康检查: %s\n", process.HealthCheck.describe())
+		fmt.Printf("  健康状态: %s (连续失败 %d 次)\n", health, process.HealthFailures)
+		if process.HealthMessage != "" {
+			fmt.Printf("  上次检查失败原因: %s\n", process.HealthMessage)
+		}
+	}
+	if process.WatchdogTimeout > 0 {
+		signalName := process.WatchdogSignal
+		if signalName == "" {
+			signalName = defaultWatchdogSignal
+		}
+		fmt.Printf("  看门狗: 超时 %s, 超时后发送 %s\n", process.WatchdogTimeout, signalName)
+		if !process.LastWatchdogPing.IsZero() {
+			fmt.Printf("  上次心跳: %s\n", process.LastWatchdogPing.Format("2006-01-02 15:04:05"))
+		}
+	}
+	if process.PID > 0 {
+		// 读取实际生效的资源限制
+		if limits, err := readProcLimits(process.PID); err == nil {
+			fmt.Println("  资源限制 (软限制 / 硬限制):")
+			for _, name := range []string{"nofile", "nproc", "core", "as", "stack", "memlock"} {
+				fmt.Printf("    %-8s %s\n", name, limits[name])
+			}
+		}
+	}
+	if process.Interpreter != "" {
+		fmt.Printf("  解释器: %s\n", strings.TrimSpace(process.Interpreter+" "+strings.Join(process.InterpreterArgs, " ")))
+	}
+	fmt.Printf("  应用类型: %s\n", process.Type)
+	fmt.Printf("  命名空间: %s\n", process.namespace())
+	fmt.Printf("  标签: %s\n", formatLabels(process.Labels))
 	fmt.Printf("  文件监控: %t\n", process.Watch)
 	fmt.Printf("  日志文件: %s\n", process.LogFile)
 	fmt.Printf("  错误日志: %s\n", process.ErrorFile)
 
-	if len(process.Env) > 0 {
+	if process.Schedule != "" {
+		fmt.Printf("  调度计划: %s\n", process.Schedule)
+		fmt.Printf("  重叠策略: %s\n", process.Overlap)
+	}
+
+	if run := process.lastRun(); run != nil {
+		fmt.Printf("  最近一次运行:\n")
+		fmt.Printf("    编号: %d\n", run.ID)
+		fmt.Printf("    开始时间: %s\n", run.StartTime.Format("2006-01-02 15:04:05"))
+		fmt.Printf("    耗时: %s\n", formatDuration(run.Duration))
+		fmt.Printf("    退出码: %d\n", run.ExitCode)
+		if run.Error != "" {
+			fmt.Printf("    错误: %s\n", run.Error)
+		}
+		fmt.Printf("    输出: %s\n", run.LogFile)
+	}
+
+	if len(process.Events) > 0 {
+		fmt.Printf("  最近事件:\n")
+		start := len(process.Events) - 10
+		if start < 0 {
+			start = 0
+		}
+		for _, event := range process.Events[start:] {
+			fmt.Printf("    [%s] %s: %s\n", event.Time.Format("2006-01-02 15:04:05"), event.Type, event.Message)
+		}
+	}
+
+	if len(process.EnvFile) > 0 {
+		fmt.Printf("  环境变量文件: %s\n", strings.Join(process.EnvFile, ", "))
+	}
+
+	if len(process.Envs) > 0 {
+		profiles := make([]string, 0, len(process.Envs))
+		for name := range process.Envs {
+			profiles = append(profiles, name)
+		}
+		sort.Strings(profiles)
+		current := process.EnvProfile
+		if current == "" {
+			current = "-"
+		}
+		fmt.Printf("  环境配置: %s (可用: %s)\n", current, strings.Join(profiles, ", "))
+	}
+
+	if env := process.effectiveEnv(); len(env) > 0 {
 		fmt.Printf("  环境变量:\n")
-		for k, v := range process.Env {
-			fmt.Printf("    %s=%s\n", k, v)
+		for k, v := range env {
+			fmt.Printf("    %s=%s\n", k, displayEnvValue(k, v))
 		}
 	}
 }
@@ -520,7 +1443,7 @@ func runMonit(cmd *cobra.Command, args []string) {
 
 // runFlush 清空日志命令处理
 func runFlush(cmd *cobra.Command, args []string) {
-	if len(args) == 0 {
+	if len(args) == 0 && !hasSelector(cmd) {
 		// 清空所有日志
 		processes := pm.GetProcessList()
 		for _, p := range processes {
@@ -531,14 +1454,19 @@ func runFlush(cmd *cobra.Command, args []string) {
 		}
 		fmt.Println("✓ 清空所有日志")
 	} else {
-		// 清空指定进程日志
-		nameOrID := args[0]
-		err := pm.ClearLogs(nameOrID)
+		// 清空指定进程或匹配选择器的进程的日志
+		targets, err := commandTargets(cmd, targetArg(args))
 		if err != nil {
 			fmt.Printf("错误: %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Printf("✓ 清空 '%s' 日志\n", nameOrID)
+		for _, target := range targets {
+			if err := pm.ClearLogs(target.ref); err != nil {
+				fmt.Printf("错误: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("✓ 清空 '%s' 日志\n", target.name)
+		}
 	}
 }
 
@@ -702,23 +1630,33 @@ func runResurrect(cmd *cobra.Command, args []string) {
 }
 
 func runWatchEnable(cmd *cobra.Command, args []string) {
-	nameOrID := args[0]
-	err := pm.EnableWatch(nameOrID)
+	targets, err := commandTargets(cmd, targetArg(args))
 	if err != nil {
 		fmt.Printf("错误: %v\n", err)
 		os.Exit(1)
 	}
-	fmt.Printf("✓ 启用 '%s' 文件监控\n", nameOrID)
+	for _, target := range targets {
+		if err := pm.EnableWatch(target.ref); err != nil {
+			fmt.Printf("错误: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✓ 启用 '%s' 文件监控\n", target.name)
+	}
 }
 
 func runWatchDisable(cmd *cobra.Command, args []string) {
-	nameOrID := args[0]
-	err := pm.DisableWatch(nameOrID)
+	targets, err := commandTargets(cmd, targetArg(args))
 	if err != nil {
 		fmt.Printf("错误: %v\n", err)
 		os.Exit(1)
 	}
-	fmt.Printf("✓ 禁用 '%s' 文件监控\n", nameOrID)
+	for _, target := range targets {
+		if err := pm.DisableWatch(target.ref); err != nil {
+			fmt.Printf("错误: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✓ 禁用 '%s' 文件监控\n", target.name)
+	}
 }
 
 // runStopDaemon 停止守护进程
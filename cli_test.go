@@ -0,0 +1,30 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitEnvPairs(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{"A=1", []string{"A=1"}},
+		{"A=1,B=2", []string{"A=1", "B=2"}},
+		{"A=x,y", []string{"A=x,y"}},
+		{"A=x,y,B=2", []string{"A=x,y", "B=2"}},
+		{"HOSTS=a=1,b=2", []string{"HOSTS=a=1", "b=2"}},
+		{"A=1,,B=2", []string{"A=1,", "B=2"}},
+		{"A=", []string{"A="}},
+		{"A=1,bad key=2", []string{"A=1,bad key=2"}},
+		{"URL=http://x/?a=1,b", []string{"URL=http://x/?a=1,b"}},
+		{"production", []string{"production"}},
+	}
+
+	for _, tt := range tests {
+		if got := splitEnvPairs(tt.value); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitEnvPairs(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -25,17 +26,20 @@ func LoadConfig(configPath string) (*Config, error) {
 	config := &Config{}
 
 	// 根据文件扩展名选择解析器
+	decode := json.Unmarshal
 	ext := strings.ToLower(filepath.Ext(configPath))
 	switch ext {
 	case ".json":
 		err = json.Unmarshal(data, config)
 	case ".yml", ".yaml":
+		decode = yaml.Unmarshal
 		err = yaml.Unmarshal(data, config)
 	default:
 		// 尝试JSON解析
 		err = json.Unmarshal(data, config)
 		if err != nil {
 			// 如果JSON解析失败，尝试YAML
+			decode = yaml.Unmarshal
 			err = yaml.Unmarshal(data, config)
 		}
 	}
@@ -44,6 +48,21 @@ func LoadConfig(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("解析配置文件失败: %v", err)
 	}
 
+	// 收集 env_<name> 形式的环境配置
+	if err := extractEnvProfiles(data, decode, config); err != nil {
+		return nil, fmt.Errorf("解析配置文件失败: %v", err)
+	}
+
+	// 展开配置中的变量
+	if err := interpolateConfig(config, configPath); err != nil {
+		return nil, fmt.Errorf("展开配置变量失败: %v", err)
+	}
+
+	// 相对路径基于配置文件所在目录
+	if err := resolveConfigPaths(config, configPath); err != nil {
+		return nil, err
+	}
+
 	// 验证配置
 	err = validateConfig(config)
 	if err != nil {
@@ -53,6 +72,54 @@ func LoadConfig(configPath string) (*Config, error) {
 	return config, nil
 }
 
+// resolveConfigPaths 将 script、cwd、log_file 和 error_file 中的相对路径解析为基于配置文件目录的绝对路径
+// 未指定 cwd 时使用配置文件所在目录
+func resolveConfigPaths(config *Config, configPath string) error {
+	configDir, err := filepath.Abs(filepath.Dir(configPath))
+	if err != nil {
+		return fmt.Errorf("解析配置文件路径失败: %v", err)
+	}
+
+	for i := range config.Apps {
+		app := &config.Apps[i]
+		if app.Cwd == "" {
+			app.Cwd = configDir
+		}
+		app.Cwd = resolvePath(configDir, app.Cwd)
+		app.Script = resolveScriptPath(configDir, app.Script)
+		if app.LogFile != "" {
+			app.LogFile = resolvePath(configDir, app.LogFile)
+		}
+		if app.ErrorFile != "" {
+			app.ErrorFile = resolvePath(configDir, app.ErrorFile)
+		}
+	}
+	return nil
+}
+
+// resolvePath 将相对路径解析为基于 base 的绝对路径
+func resolvePath(base, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(base, path)
+}
+
+// resolveScriptPath 解析脚本路径，不含路径分隔符且在 base 下不存在的视为命令名，保持不变
+func resolveScriptPath(base, script string) string {
+	if script == "" || filepath.IsAbs(script) {
+		return script
+	}
+	path := filepath.Join(base, script)
+	if strings.ContainsRune(script, '/') || strings.ContainsRune(script, filepath.Separator) {
+		return path
+	}
+	if _, err := os.Stat(path); err == nil {
+		return path
+	}
+	return script
+}
+
 // SaveConfig 保存配置到文件
 func SaveConfig(config *Config, configPath string) error {
 	var data []byte
@@ -91,8 +158,14 @@ func validateConfig(config *Config) error {
 		if app.Name == "" {
 			return fmt.Errorf("应用 %d: 名称不能为空", i)
 		}
-		if app.Script == "" {
-			return fmt.Errorf("应用 '%s': 脚本路径不能为空", app.Name)
+		if err := validateAppName(app.Name); err != nil {
+			return fmt.Errorf("应用 %d: %v", i, err)
+		}
+		if app.Script == "" && app.Command == "" {
+			return fmt.Errorf("应用 '%s': script 和 command 必须指定其一", app.Name)
+		}
+		if app.Script != "" && app.Command != "" {
+			return fmt.Errorf("应用 '%s': script 和 command 不能同时指定", app.Name)
 		}
 
 		// 检查名称唯一性
@@ -101,9 +174,15 @@ func validateConfig(config *Config) error {
 		}
 		appNames[app.Name] = true
 
-		// 检查脚本文件是否存在
-		if _, err := os.Stat(app.Script); os.IsNotExist(err) {
-			return fmt.Errorf("应用 '%s': 脚本文件不存在: %s", app.Name, app.Script)
+		// 检查脚本文件是否存在，直接执行的命令名按应用的 PATH 查找，shell 命令由 /bin/sh 解析
+		if app.Script != "" {
+			if isBareCommand(app.Script) && (app.Interpreter == "" || app.Interpreter == interpreterNone) {
+				if _, err := lookPathIn(app.Script, appPath(app), app.Cwd); err != nil {
+					return fmt.Errorf("应用 '%s': %v", app.Name, err)
+				}
+			} else if _, err := os.Stat(app.Script); os.IsNotExist(err) {
+				return fmt.Errorf("应用 '%s': 脚本文件不存在: %s", app.Name, app.Script)
+			}
 		}
 
 		// 验证instances数量
@@ -115,11 +194,211 @@ func validateConfig(config *Config) error {
 		if app.ExecMode != "" && app.ExecMode != "fork" && app.ExecMode != "cluster" {
 			return fmt.Errorf("应用 '%s': 不支持的执行模式: %s", app.Name, app.ExecMode)
 		}
+
+		// 验证应用类型
+		if app.Type != "" && app.Type != string(AppTypeService) && app.Type != string(AppTypeJob) {
+			return fmt.Errorf("应用 '%s': 不支持的应用类型: %s", app.Name, app.Type)
+		}
+
+		// 验证运行用户和组
+		if _, err := resolveCredential(app.User, app.Group, app.Groups); err != nil {
+			return fmt.Errorf("应用 '%s': %v", app.Name, err)
+		}
+
+		// 验证环境配置和密钥引用
+		if app.EnvProfile != "" && !hasEnvProfile(app.Envs, app.EnvProfile) {
+			return fmt.Errorf("应用 '%s': 未定义环境配置: %s", app.Name, app.EnvProfile)
+		}
+		if err := validateSecretRefs(app.Env); err != nil {
+			return fmt.Errorf("应用 '%s': %v", app.Name, err)
+		}
+		for _, env := range app.Envs {
+			if err := validateSecretRefs(env); err != nil {
+				return fmt.Errorf("应用 '%s': %v", app.Name, err)
+			}
+		}
+
+		// 验证资源限制
+		if _, err := app.Limits.entries(); err != nil {
+			return fmt.Errorf("应用 '%s': %v", app.Name, err)
+		}
+		if _, err := app.Cgroup.files(); err != nil {
+			return fmt.Errorf("应用 '%s': %v", app.Name, err)
+		}
+
+		if app.Port < 0 || app.Port > 65535 {
+			return fmt.Errorf("应用 '%s': port 无效: %d", app.Name, app.Port)
+		}
+
+		// 验证看门狗
+		if app.WatchdogTimeout != "" {
+			if app.Type == string(AppTypeJob) {
+				return fmt.Errorf("应用 '%s': watchdog_timeout 不适用于 type: job", app.Name)
+			}
+			if timeout, err := time.ParseDuration(app.WatchdogTimeout); err != nil || timeout < time.Second {
+				return fmt.Errorf("应用 '%s': watchdog_timeout 无效或小于1秒: %s", app.Name, app.WatchdogTimeout)
+			}
+		}
+		if app.WatchdogSignal != "" {
+			if _, _, err := parseSignal(app.WatchdogSignal); err != nil {
+				return fmt.Errorf("应用 '%s': watchdog_signal: %v", app.Name, err)
+			}
+		}
+
+		// 验证健康检查
+		if app.HealthCheck != nil {
+			if app.Type == string(AppTypeJob) {
+				return fmt.Errorf("应用 '%s': health_check 不适用于 type: job", app.Name)
+			}
+			if _, err := app.HealthCheck.settings(); err != nil {
+				return fmt.Errorf("应用 '%s': %v", app.Name, err)
+			}
+		}
+
+		// 验证标签
+		for key := range app.Labels {
+			if key == "" || strings.ContainsAny(key, "=!,") {
+				return fmt.Errorf("应用 '%s': 无效的标签名: '%s'", app.Name, key)
+			}
+		}
+
+		// 验证依赖
+		for _, dep := range app.DependsOn {
+			if dep == app.Name {
+				return fmt.Errorf("应用 '%s': 不能依赖自身", app.Name)
+			}
+			if !appDefined(config.Apps, dep) {
+				return fmt.Errorf("应用 '%s': 依赖的应用 '%s' 未在配置文件中定义", app.Name, dep)
+			}
+		}
+
+		// 验证钩子超时时间
+		if app.HookTimeout != "" {
+			if _, err := time.ParseDuration(app.HookTimeout); err != nil {
+				return fmt.Errorf("应用 '%s': 钩子超时时间无效: %s", app.Name, app.HookTimeout)
+			}
+		}
+
+		// 验证任务调度配置
+		if app.Type != string(AppTypeJob) && (app.Schedule != "" || app.Overlap != "" || app.MaxRunTime != "") {
+			return fmt.Errorf("应用 '%s': schedule/overlap/max_run_time 仅适用于 type: job", app.Name)
+		}
+		if app.Schedule != "" {
+			if _, err := ParseCron(app.Schedule); err != nil {
+				return fmt.Errorf("应用 '%s': 调度表达式无效: %v", app.Name, err)
+			}
+		}
+		switch OverlapPolicy(app.Overlap) {
+		case "", OverlapSkip, OverlapQueue, OverlapAllow:
+		default:
+			return fmt.Errorf("应用 '%s': 不支持的重叠策略: %s", app.Name, app.Overlap)
+		}
+		if app.MaxRunTime != "" {
+			if _, err := time.ParseDuration(app.MaxRunTime); err != nil {
+				return fmt.Errorf("应用 '%s': 最大运行时间无效: %s", app.Name, app.MaxRunTime)
+			}
+		}
+		if app.KeepRuns < 0 {
+			return fmt.Errorf("应用 '%s': keep_runs 不能为负数", app.Name)
+		}
 	}
 
+	// 检查循环依赖
+	if _, err := sortAppsByDependency(config.Apps); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateAppName 检查应用名称能否安全地用作数据目录和 cgroup 下的目录名
+func validateAppName(name string) error {
+	if name == "" || name == "." || name == ".." {
+		return fmt.Errorf("无效的应用名称: '%s'", name)
+	}
+	for _, r := range name {
+		if r == '/' || r == '\\' || r < 0x20 || r == 0x7f {
+			return fmt.Errorf("应用名称 '%s' 不能包含路径分隔符或控制字符", name)
+		}
+	}
 	return nil
 }
 
+// appDefined 检查配置中是否定义了指定名称的应用
+func appDefined(apps []AppConfig, name string) bool {
+	for _, app := range apps {
+		if app.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// sortAppsByDependency 按依赖关系排序应用，被依赖的应用排在前面
+func sortAppsByDependency(apps []AppConfig) ([]AppConfig, error) {
+	names := make([]string, 0, len(apps))
+	deps := make(map[string][]string)
+	byName := make(map[string]AppConfig)
+	for _, app := range apps {
+		names = append(names, app.Name)
+		deps[app.Name] = app.DependsOn
+		byName[app.Name] = app
+	}
+
+	order, err := dependencyOrder(names, deps)
+	if err != nil {
+		return nil, err
+	}
+
+	sorted := make([]AppConfig, 0, len(order))
+	for _, name := range order {
+		sorted = append(sorted, byName[name])
+	}
+	return sorted, nil
+}
+
+// dependencyOrder 对名称做拓扑排序，无依赖关系的名称保持原有顺序
+func dependencyOrder(names []string, deps map[string][]string) ([]string, error) {
+	known := make(map[string]bool)
+	for _, name := range names {
+		known[name] = true
+	}
+
+	// 0 未访问，1 访问中，2 已完成
+	state := make(map[string]int)
+	order := make([]string, 0, len(names))
+
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case 1:
+			return fmt.Errorf("存在循环依赖: %s", strings.Join(append(path, name), " -> "))
+		case 2:
+			return nil
+		}
+
+		state[name] = 1
+		for _, dep := range deps[name] {
+			if !known[dep] {
+				continue
+			}
+			if err := visit(dep, append(path, name)); err != nil {
+				return err
+			}
+		}
+		state[name] = 2
+		order = append(order, name)
+		return nil
+	}
+
+	for _, name := range names {
+		if err := visit(name, nil); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
 // GenerateConfigTemplate 生成配置文件模板
 func GenerateConfigTemplate(configPath string) error {
 	template := &Config{
@@ -154,13 +433,29 @@ func FromProcessToAppConfig(p *Process) AppConfig {
 	if p.MinUptime > 0 {
 		minUptime = p.MinUptime.String()
 	}
+	hookTimeout := ""
+	if p.HookTimeout > 0 {
+		hookTimeout = p.HookTimeout.String()
+	}
+	maxRunTime := ""
+	if p.MaxRunTime > 0 {
+		maxRunTime = p.MaxRunTime.String()
+	}
+	watchdogTimeout := ""
+	if p.WatchdogTimeout > 0 {
+		watchdogTimeout = p.WatchdogTimeout.String()
+	}
 
 	return AppConfig{
 		Name:        p.Name,
 		Script:      p.Script,
+		Command:     p.Command,
 		Args:        p.Args,
 		Cwd:         p.Cwd,
 		Env:         p.Env,
+		EnvFile:     p.EnvFile,
+		Envs:        p.Envs,
+		EnvProfile:  p.EnvProfile,
 		Instances:   p.Instances,
 		ExecMode:    string(p.ExecMode),
 		Watch:       p.Watch,
@@ -169,6 +464,33 @@ func FromProcessToAppConfig(p *Process) AppConfig {
 		ErrorFile:   p.ErrorFile,
 		MaxRestarts: p.MaxRestarts,
 		MinUptime:   minUptime,
+		Type:        string(p.Type),
+		Schedule:    p.Schedule,
+		Overlap:     string(p.Overlap),
+		MaxRunTime:  maxRunTime,
+		KeepRuns:    p.KeepRuns,
+		Namespace:   p.Namespace,
+		Labels:      p.Labels,
+		DependsOn:   p.DependsOn,
+		PreStart:    p.PreStart,
+		PostStart:   p.PostStart,
+		PreStop:     p.PreStop,
+		PostStop:    p.PostStop,
+		HookTimeout: hookTimeout,
+
+		Interpreter:     p.Interpreter,
+		InterpreterArgs: p.InterpreterArgs,
+
+		User:   p.User,
+		Group:  p.Group,
+		Groups: p.Groups,
+		Limits: p.Limits,
+		Cgroup: p.Cgroup,
+
+		HealthCheck:     p.HealthCheck,
+		WatchdogTimeout: watchdogTimeout,
+		WatchdogSignal:  p.WatchdogSignal,
+		Port:            p.Port,
 	}
 }
 
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDependencyOrder(t *testing.T) {
+	tests := []struct {
+		name    string
+		names   []string
+		deps    map[string][]string
+		want    []string
+		wantErr string
+	}{
+		{"no deps keeps order", []string{"c", "a", "b"}, nil, []string{"c", "a", "b"}, ""},
+		{"dependency first", []string{"api", "db"}, map[string][]string{"api": {"db"}}, []string{"db", "api"}, ""},
+		{"chain", []string{"web", "api", "db"}, map[string][]string{"web": {"api"}, "api": {"db"}}, []string{"db", "api", "web"}, ""},
+		{"diamond", []string{"web", "api", "worker", "db"}, map[string][]string{
+			"web":    {"api", "worker"},
+			"api":    {"db"},
+			"worker": {"db"},
+		}, []string{"db", "api", "worker", "web"}, ""},
+		{"unknown dependency ignored", []string{"api"}, map[string][]string{"api": {"missing"}}, []string{"api"}, ""},
+		{"duplicate names", []string{"api", "api", "db"}, map[string][]string{"api": {"db"}}, []string{"db", "api"}, ""},
+
+		{"self cycle", []string{"a"}, map[string][]string{"a": {"a"}}, nil, "a -> a"},
+		{"two cycle", []string{"a", "b"}, map[string][]string{"a": {"b"}, "b": {"a"}}, nil, "a -> b -> a"},
+		{"cycle behind dependency", []string{"web", "a", "b"}, map[string][]string{
+			"web": {"a"},
+			"a":   {"b"},
+			"b":   {"a"},
+		}, nil, "web -> a -> b -> a"},
+	}
+
+	for _, tt := range tests {
+		got, err := dependencyOrder(tt.names, tt.deps)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: dependencyOrder error = %v, want %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: dependencyOrder error = %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: dependencyOrder = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
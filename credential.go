@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"os/user"
+	"strconv"
+)
+
+// credential 运行应用的用户和组
+type credential struct {
+	uid    uint32
+	gid    uint32
+	groups []uint32
+}
+
+// resolveCredential 解析用户、组和附加组，支持名称和数字 ID，未指定用户时返回 nil
+// 未指定组时使用用户的主组，未指定附加组时使用用户所属的全部组
+func resolveCredential(userName, groupName string, groups []string) (*credential, error) {
+	if userName == "" {
+		if groupName != "" || len(groups) > 0 {
+			return nil, fmt.Errorf("指定 group 时必须同时指定 user")
+		}
+		return nil, nil
+	}
+
+	u, err := lookupUser(userName)
+	if err != nil {
+		return nil, err
+	}
+
+	uid, err := strconv.ParseUint(u.Uid, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("无效的用户 ID '%s': %v", u.Uid, err)
+	}
+	cred := &credential{uid: uint32(uid)}
+
+	gidStr := u.Gid
+	if groupName != "" {
+		g, err := lookupGroup(groupName)
+		if err != nil {
+			return nil, err
+		}
+		gidStr = g.Gid
+	}
+	gid, err := strconv.ParseUint(gidStr, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("无效的组 ID '%s': %v", gidStr, err)
+	}
+	cred.gid = uint32(gid)
+
+	groupIDs := make([]string, 0, len(groups))
+	if len(groups) > 0 {
+		for _, name := range groups {
+			g, err := lookupGroup(name)
+			if err != nil {
+				return nil, err
+			}
+			groupIDs = append(groupIDs, g.Gid)
+		}
+	} else if ids, err := u.GroupIds(); err == nil {
+		groupIDs = ids
+	}
+
+	for _, idStr := range groupIDs {
+		id, err := strconv.ParseUint(idStr, 10, 32)
+		if err != nil {
+			continue
+		}
+		cred.groups = append(cred.groups, uint32(id))
+	}
+
+	return cred, nil
+}
+
+// lookupUser 按名称或数字 ID 查找用户
+func lookupUser(name string) (*user.User, error) {
+	u, err := user.Lookup(name)
+	if err == nil {
+		return u, nil
+	}
+	if _, convErr := strconv.Atoi(name); convErr == nil {
+		if u, err := user.LookupId(name); err == nil {
+			return u, nil
+		}
+	}
+	return nil, fmt.Errorf("未知用户: %s", name)
+}
+
+// lookupGroup 按名称或数字 ID 查找组
+func lookupGroup(name string) (*user.Group, error) {
+	g, err := user.LookupGroup(name)
+	if err == nil {
+		return g, nil
+	}
+	if _, convErr := strconv.Atoi(name); convErr == nil {
+		if g, err := user.LookupGroupId(name); err == nil {
+			return g, nil
+		}
+	}
+	return nil, fmt.Errorf("未知用户组: %s", name)
+}
+
+// processCredential 解析进程配置的运行用户（调用方需持有 p.mutex）
+func (p *Process) processCredential() (*credential, error) {
+	return resolveCredential(p.User, p.Group, p.Groups)
+}
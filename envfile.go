@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// loadEnvFiles 按顺序读取应用的 env_file，后面的文件覆盖前面的同名变量
+// 每次启动时重新读取，修改文件后重启即可生效
+func loadEnvFiles(p *Process) (map[string]string, error) {
+	env := make(map[string]string)
+	for _, file := range p.EnvFile {
+		path := file
+		if !filepath.IsAbs(path) && p.Cwd != "" {
+			path = filepath.Join(p.Cwd, path)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("读取 env_file 失败: %v", err)
+		}
+
+		lookup := func(key string) (string, bool) {
+			if value, exists := env[key]; exists {
+				return value, true
+			}
+			return os.LookupEnv(key)
+		}
+
+		if err := parseDotenv(string(data), lookup, env); err != nil {
+			return nil, fmt.Errorf("解析 env_file '%s' 失败: %v", file, err)
+		}
+	}
+	return env, nil
+}
+
+// parseDotenv 按 dotenv 规则解析内容并写入 env
+// 支持 export 前缀、# 注释、单引号（原样）、双引号（转义和多行）以及 ${VAR}/$VAR 变量展开
+func parseDotenv(content string, lookup func(string) (string, bool), env map[string]string) error {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	lines := strings.Split(content, "\n")
+
+	for i := 0; i < len(lines); i++ {
+		lineNo := i + 1
+		line := strings.TrimSpace(lines[i])
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		idx := strings.Index(line, "=")
+		if idx <= 0 {
+			return fmt.Errorf("第 %d 行格式无效: %s", lineNo, line)
+		}
+		key := strings.TrimSpace(line[:idx])
+		if strings.ContainsAny(key, " \t\"'$") {
+			return fmt.Errorf("第 %d 行变量名无效: %s", lineNo, key)
+		}
+		raw := strings.TrimSpace(line[idx+1:])
+
+		var value string
+		switch {
+		case strings.HasPrefix(raw, "'"):
+			// 单引号中的内容原样保留
+			end := strings.Index(raw[1:], "'")
+			if end < 0 {
+				return fmt.Errorf("第 %d 行缺少结束的单引号", lineNo)
+			}
+			value = raw[1 : end+1]
+
+		case strings.HasPrefix(raw, `"`):
+			// 双引号支持转义和跨行
+			quoted := raw[1:]
+			for {
+				if end := closingQuote(quoted); end >= 0 {
+					quoted = quoted[:end]
+					break
+				}
+				i++
+				if i >= len(lines) {
+					return fmt.Errorf("第 %d 行缺少结束的双引号", lineNo)
+				}
+				quoted += "\n" + lines[i]
+			}
+			value = expandEnv(unescapeDotenv(quoted), lookup)
+
+		default:
+			// 未加引号的值去掉行内注释
+			if idx := strings.Index(raw, " #"); idx >= 0 {
+				raw = strings.TrimSpace(raw[:idx])
+			}
+			value = expandEnv(raw, lookup)
+		}
+
+		env[key] = value
+	}
+	return nil
+}
+
+// closingQuote 返回第一个未转义的双引号位置
+func closingQuote(s string) int {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+// unescapeDotenv 处理双引号中的转义字符
+func unescapeDotenv(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 >= len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case '$':
+			// 保留转义，避免被展开
+			b.WriteString(`\$`)
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// expandEnv 展开 ${VAR} 和 $VAR，\$ 表示字面量 $
+func expandEnv(s string, lookup func(string) (string, bool)) string {
+	const placeholder = "\x00"
+	s = strings.ReplaceAll(s, `\$`, placeholder)
+	s = os.Expand(s, func(key string) string {
+		value, _ := lookup(key)
+		return value
+	})
+	return strings.ReplaceAll(s, placeholder, "$")
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDotenv(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]string
+		wantErr bool
+	}{
+		{"basic", "A=1\nB=two", map[string]string{"A": "1", "B": "two"}, false},
+		{"empty", "", map[string]string{}, false},
+		{"comments and blank lines", "# comment\n\n  A=1  \n#B=2", map[string]string{"A": "1"}, false},
+		{"export prefix", "export A=1", map[string]string{"A": "1"}, false},
+		{"crlf", "A=1\r\nB=2\r\n", map[string]string{"A": "1", "B": "2"}, false},
+		{"empty value", "A=", map[string]string{"A": ""}, false},
+		{"inline comment", "A=1 # note", map[string]string{"A": "1"}, false},
+		{"hash without space", "A=a#b", map[string]string{"A": "a#b"}, false},
+		{"equals in value", "A=x=y", map[string]string{"A": "x=y"}, false},
+		{"single quotes are literal", `A='$HOME \n # x'`, map[string]string{"A": `$HOME \n # x`}, false},
+		{"double quote escapes", `A="a\nb\t\"c\""`, map[string]string{"A": "a\nb\t\"c\""}, false},
+		{"double quote multiline", "A=\"line1\nline2\"\nB=3", map[string]string{"A": "line1\nline2", "B": "3"}, false},
+		{"expand earlier vars", "A=1\nB=${A}-$A", map[string]string{"A": "1", "B": "1-1"}, false},
+		{"expand in double quotes", "A=x\nB=\"${A}y\"", map[string]string{"A": "x", "B": "xy"}, false},
+		{"undefined expands to empty", "A=${MISSING}", map[string]string{"A": ""}, false},
+		{"escaped dollar", `A="\$X"`, map[string]string{"A": "$X"}, false},
+		{"later value wins", "A=1\nA=2", map[string]string{"A": "2"}, false},
+
+		{"missing equals", "NOEQUALS", nil, true},
+		{"empty key", "=x", nil, true},
+		{"key with space", "BAD KEY=1", nil, true},
+		{"key with dollar", "$A=1", nil, true},
+		{"unterminated single quote", "A='x", nil, true},
+		{"unterminated double quote", "A=\"x\nB=1", nil, true},
+	}
+
+	for _, tt := range tests {
+		env := make(map[string]string)
+		lookup := func(key string) (string, bool) {
+			value, exists := env[key]
+			return value, exists
+		}
+		err := parseDotenv(tt.content, lookup, env)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: parseDotenv error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(env, tt.want) {
+			t.Errorf("%s: parseDotenv = %q, want %q", tt.name, env, tt.want)
+		}
+	}
+}
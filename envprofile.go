@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 配置文件中以此前缀命名的字段为环境配置，如 env_production
+const envProfilePrefix = "env_"
+
+// extractEnvProfiles 将配置文件中 env_<name> 形式的字段合并到应用的 envs 中
+// decode 为解析配置文件所用的函数（JSON 或 YAML）
+func extractEnvProfiles(data []byte, decode func([]byte, interface{}) error, config *Config) error {
+	var raw struct {
+		Apps []map[string]interface{} `json:"apps" yaml:"apps"`
+	}
+	if err := decode(data, &raw); err != nil {
+		return err
+	}
+
+	for i, fields := range raw.Apps {
+		if i >= len(config.Apps) {
+			break
+		}
+		app := &config.Apps[i]
+		for key, value := range fields {
+			if !strings.HasPrefix(key, envProfilePrefix) || key == "env_file" || key == "env_profile" {
+				continue
+			}
+			name := strings.TrimPrefix(key, envProfilePrefix)
+
+			vars, ok := value.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("应用 '%s': %s 必须是键值对", app.Name, key)
+			}
+			if app.Envs == nil {
+				app.Envs = make(map[string]map[string]string)
+			}
+			if app.Envs[name] == nil {
+				app.Envs[name] = make(map[string]string)
+			}
+			for k, v := range vars {
+				app.Envs[name][k] = fmt.Sprint(v)
+			}
+		}
+	}
+	return nil
+}
+
+// hasEnvProfile 检查应用是否定义了指定的环境配置
+func hasEnvProfile(envs map[string]map[string]string, name string) bool {
+	_, exists := envs[name]
+	return exists
+}
+
+// switchEnvProfile 切换应用的环境配置，在下次启动时生效
+func (pm *ProcessManager) switchEnvProfile(p *Process, name string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if !hasEnvProfile(p.Envs, name) {
+		return fmt.Errorf("未定义环境配置: %s", name)
+	}
+	if p.EnvProfile != name {
+		pm.recordEvent(p, "env", fmt.Sprintf("环境配置切换为 %s", name))
+		p.EnvProfile = name
+	}
+	return nil
+}
+
+// effectiveEnv 返回合并环境配置后的 env，环境配置中的同名变量覆盖基础 env
+func (p *Process) effectiveEnv() map[string]string {
+	profile := p.Envs[p.EnvProfile]
+	if len(profile) == 0 {
+		return p.Env
+	}
+
+	env := make(map[string]string, len(p.Env)+len(profile))
+	for key, value := range p.Env {
+		env[key] = value
+	}
+	for key, value := range profile {
+		env[key] = value
+	}
+	return env
+}
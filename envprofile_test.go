@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestExtractEnvProfiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    map[string]map[string]string
+		wantErr bool
+	}{
+		{"no profiles", `{"apps": [{"name": "api", "env": {"A": "1"}}]}`, nil, false},
+		{"profiles", `{"apps": [{"name": "api", "env_production": {"A": "2", "PORT": 80}, "env_staging": {"A": "3"}}]}`,
+			map[string]map[string]string{
+				"production": {"A": "2", "PORT": "80"},
+				"staging":    {"A": "3"},
+			}, false},
+		{"merged with envs", `{"apps": [{"name": "api", "envs": {"production": {"A": "1", "B": "1"}}, "env_production": {"B": "2"}}]}`,
+			map[string]map[string]string{"production": {"A": "1", "B": "2"}}, false},
+		{"env_file and env_profile are not profiles", `{"apps": [{"name": "api", "env_file": [".env"], "env_profile": "production"}]}`, nil, false},
+
+		{"profile must be a map", `{"apps": [{"name": "api", "env_production": "x"}]}`, nil, true},
+	}
+
+	for _, tt := range tests {
+		var config Config
+		if err := json.Unmarshal([]byte(tt.data), &config); err != nil {
+			t.Fatalf("%s: json.Unmarshal error = %v", tt.name, err)
+		}
+		err := extractEnvProfiles([]byte(tt.data), json.Unmarshal, &config)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: extractEnvProfiles error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(config.Apps[0].Envs, tt.want) {
+			t.Errorf("%s: envs = %v, want %v", tt.name, config.Apps[0].Envs, tt.want)
+		}
+	}
+}
+
+func TestEffectiveEnv(t *testing.T) {
+	envs := map[string]map[string]string{
+		"production": {"B": "prod", "C": "3"},
+		"empty":      {},
+	}
+
+	tests := []struct {
+		profile string
+		want    map[string]string
+	}{
+		{"", map[string]string{"A": "1", "B": "base"}},
+		{"production", map[string]string{"A": "1", "B": "prod", "C": "3"}},
+		{"empty", map[string]string{"A": "1", "B": "base"}},
+		{"missing", map[string]string{"A": "1", "B": "base"}},
+	}
+
+	for _, tt := range tests {
+		p := &Process{Env: map[string]string{"A": "1", "B": "base"}, Envs: envs, EnvProfile: tt.profile}
+		if got := p.effectiveEnv(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("effectiveEnv(%q) = %v, want %v", tt.profile, got, tt.want)
+		}
+		if p.Env["B"] != "base" {
+			t.Errorf("effectiveEnv(%q) modified the base env", tt.profile)
+		}
+	}
+
+	for _, name := range []string{"production", "empty"} {
+		if !hasEnvProfile(envs, name) {
+			t.Errorf("hasEnvProfile(%q) = false, want true", name)
+		}
+	}
+	if hasEnvProfile(envs, "missing") || hasEnvProfile(nil, "production") {
+		t.Errorf("hasEnvProfile reported an undefined profile")
+	}
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// buildError Go 应用编译失败
+type buildError struct {
+	err error
+}
+
+func (e *buildError) Error() string {
+	return fmt.Sprintf("编译失败: %v", e.err)
+}
+
+// startFailedStatus 返回启动失败后应设置的状态，编译失败单独标记
+func startFailedStatus(err error) ProcessStatus {
+	var be *buildError
+	if errors.As(err, &be) {
+		return StatusBuildFailed
+	}
+	return StatusErrored
+}
+
+// isGoRun 判断解释器是否为 go run
+func isGoRun(interpreter string, args []string) bool {
+	return filepath.Base(interpreter) == "go" && len(args) > 0 && args[0] == "run"
+}
+
+// buildGoBinary 将 Go 应用编译到数据目录下的缓存中，源码未变化时直接复用
+// goCmd 为配置的 go 解释器，按应用环境中的 PATH 查找，flags 为 go run 之后的编译参数
+// 调用方需持有 p.mutex，计算源码哈希和编译期间会释放锁，期间应用被停止或删除时返回错误
+func (pm *ProcessManager) buildGoBinary(ctx context.Context, p *Process, goCmd string, flags []string, stderr io.Writer) (string, error) {
+	cacheDir, err := pm.appDataDir("build", p.Name)
+	if err != nil {
+		return "", err
+	}
+	env, err := goBuildEnv(p)
+	if err != nil {
+		return "", err
+	}
+	goPath, err := lookPathIn(goCmd, envPath(env), p.Cwd)
+	if err != nil {
+		return "", err
+	}
+	build := goBuild{
+		goPath:   goPath,
+		script:   p.Script,
+		path:     p.scriptPath(),
+		cwd:      p.Cwd,
+		flags:    flags,
+		env:      env,
+		cacheDir: cacheDir,
+	}
+	stops := p.stops
+
+	p.mutex.Unlock()
+	startTime := time.Now()
+	binary, built, err := build.run(ctx, stderr)
+	p.mutex.Lock()
+
+	if err == nil && (p.stops != stops || p.deleted) {
+		err = fmt.Errorf("应用 '%s' 在编译期间已被停止或删除", p.Name)
+	}
+	if err != nil {
+		var be *buildError
+		if errors.As(err, &be) {
+			pm.recordEvent(p, "build", err.Error())
+		}
+		return "", err
+	}
+	if built {
+		pm.recordEvent(p, "build", fmt.Sprintf("编译成功 (耗时 %s)", time.Since(startTime).Round(time.Millisecond)))
+	}
+	return binary, nil
+}
+
+// 编译时从应用的 env_file 和 env 中传给 go 命令的变量，其余变量（包括密钥）只在运行应用时设置
+var goBuildEnvVars = map[string]bool{
+	"PATH": true, "HOME": true, "TMPDIR": true, "CC": true, "CXX": true, "PKG_CONFIG_PATH": true,
+	"GOPATH": true, "GOROOT": true, "GOBIN": true, "GOCACHE": true, "GOMODCACHE": true, "GOTMPDIR": true, "GOENV": true,
+	"GOFLAGS": true, "GO111MODULE": true, "GOTOOLCHAIN": true, "GOWORK": true, "GOEXPERIMENT": true,
+	"GOOS": true, "GOARCH": true, "GOAMD64": true, "GOARM": true, "GOARM64": true, "GO386": true,
+	"GOPROXY": true, "GOPRIVATE": true, "GONOPROXY": true, "GONOSUMDB": true, "GOSUMDB": true, "GOINSECURE": true,
+	"CGO_ENABLED": true, "CGO_CFLAGS": true, "CGO_CPPFLAGS": true, "CGO_CXXFLAGS": true, "CGO_LDFLAGS": true,
+	"HTTP_PROXY": true, "HTTPS_PROXY": true, "NO_PROXY": true, "http_proxy": true, "https_proxy": true, "no_proxy": true,
+}
+
+// goBuildEnv 构建 go 命令的环境变量：守护进程的环境加上应用配置中与编译相关的变量
+// 不解析密钥引用，密钥引用形式的取值直接跳过
+func goBuildEnv(p *Process) ([]string, error) {
+	fileEnv, err := loadEnvFiles(p)
+	if err != nil {
+		return nil, err
+	}
+
+	env := os.Environ()
+	for _, vars := range []map[string]string{fileEnv, p.effectiveEnv()} {
+		for key, value := range vars {
+			known := goBuildEnvVars[key] || (runtime.GOOS == "windows" && goBuildEnvVars[strings.ToUpper(key)])
+			if known && !isSecretRef(value) {
+				env = append(env, fmt.Sprintf("%s=%s", key, value))
+			}
+		}
+	}
+	return env, nil
+}
+
+// goBuild 一次编译所需的应用配置快照，编译时不访问进程记录
+type goBuild struct {
+	goPath   string // go 命令的路径
+	script   string // 传给 go build 的脚本或包路径
+	path     string // 脚本的绝对路径
+	cwd      string
+	flags    []string
+	env      []string
+	cacheDir string
+}
+
+// run 返回源码对应的缓存二进制，缓存不存在时编译，built 表示本次进行了编译
+// 编译输出写入 stderr
+func (b goBuild) run(ctx context.Context, stderr io.Writer) (string, bool, error) {
+	hash, err := b.sourceHash(ctx)
+	if err != nil {
+		return "", false, &buildError{err: err}
+	}
+
+	binary := filepath.Join(b.cacheDir, hash)
+	if runtime.GOOS == "windows" {
+		binary += ".exe"
+	}
+	if _, err := os.Stat(binary); err == nil {
+		return binary, false, nil
+	}
+
+	if err := os.MkdirAll(b.cacheDir, 0755); err != nil {
+		return "", false, &buildError{err: err}
+	}
+
+	// 先编译到临时文件，避免留下不完整的二进制
+	tmpFile := binary + ".tmp"
+	args := append([]string{"build", "-o", tmpFile}, b.flags...)
+	args = append(args, b.script)
+
+	cmd := exec.CommandContext(ctx, b.goPath, args...)
+	cmd.Dir = b.cwd
+	cmd.Env = b.env
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		os.Remove(tmpFile)
+		if stderr != nil {
+			fmt.Fprintf(stderr, "[%s] go %s 失败:\n%s\n", time.Now().Format("2006-01-02 15:04:05"),
+				strings.Join(args, " "), output)
+		}
+		return "", false, &buildError{err: err}
+	}
+
+	if err := os.Rename(tmpFile, binary); err != nil {
+		os.Remove(tmpFile)
+		return "", false, &buildError{err: err}
+	}
+
+	// 清理旧版本的缓存
+	if entries, err := os.ReadDir(b.cacheDir); err == nil {
+		for _, entry := range entries {
+			if entry.Name() != filepath.Base(binary) {
+				os.Remove(filepath.Join(b.cacheDir, entry.Name()))
+			}
+		}
+	}
+
+	return binary, true, nil
+}
+
+// goListTemplate 输出主模块内（或不属于任何模块）的依赖包的目录、go.mod 和参与编译的文件，以 | 分隔
+// 模块缓存中的依赖由 go.sum 确定版本，不需要读取
+const goListTemplate = `{{if and (not .Standard) (or (not .Module) .Module.Main)}}` +
+	`{{.Dir}}|{{with .Module}}{{.GoMod}}{{end}}` +
+	`{{range .GoFiles}}|{{.}}{{end}}{{range .CgoFiles}}|{{.}}{{end}}{{range .CFiles}}|{{.}}{{end}}` +
+	`{{range .HFiles}}|{{.}}{{end}}{{range .EmbedFiles}}|{{.}}{{end}}{{end}}`
+
+// sourceHash 计算参与编译的源码、go.mod/go.sum、Go 工具链版本以及编译参数的哈希
+// 文件列表由 go list 按编译参数确定，不会读取与应用无关的目录
+func (b goBuild) sourceHash(ctx context.Context) (string, error) {
+	// 升级 Go 或 GOTOOLCHAIN 切换版本后需要重新编译
+	version, err := b.goOutput(ctx, "env", "GOVERSION")
+	if err != nil {
+		return "", err
+	}
+
+	args := append([]string{"list", "-deps", "-f", goListTemplate}, b.flags...)
+	args = append(args, b.script)
+	output, err := b.goOutput(ctx, args...)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00%s\x00", b.path, strings.Join(b.flags, "\x00"), runtime.GOOS+"/"+runtime.GOARCH,
+		strings.TrimSpace(version))
+
+	var files []string
+	modules := make(map[string]bool)
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		parts := strings.Split(line, "|")
+		if len(parts) < 2 {
+			continue
+		}
+		dir := parts[0]
+		for _, name := range parts[2:] {
+			files = append(files, filepath.Join(dir, name))
+		}
+		if goMod := parts[1]; goMod != "" && !modules[goMod] {
+			modules[goMod] = true
+			files = append(files, goMod, filepath.Join(filepath.Dir(goMod), "go.sum"))
+		}
+	}
+	sort.Strings(files)
+
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			// 没有依赖的模块可以不存在 go.sum
+			if os.IsNotExist(err) && filepath.Base(path) == "go.sum" {
+				continue
+			}
+			return "", fmt.Errorf("读取源码失败: %v", err)
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00", path, len(data))
+		h.Write(data)
+	}
+
+	return hex.EncodeToString(h.Sum(nil))[:16], nil
+}
+
+// goOutput 在应用目录下执行 go 命令并返回标准输出，失败时返回 go 输出的错误信息
+func (b goBuild) goOutput(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, b.goPath, args...)
+	cmd.Dir = b.cwd
+	cmd.Env = b.env
+	output, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("%s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", err
+	}
+	return string(output), nil
+}
@@ -0,0 +1,377 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// HealthStatus 健康检查状态
+type HealthStatus string
+
+const (
+	HealthStarting  HealthStatus = "starting"
+	HealthHealthy   HealthStatus = "healthy"
+	HealthUnhealthy HealthStatus = "unhealthy"
+)
+
+// 健康检查默认值
+const (
+	defaultHealthInterval  = 10 * time.Second
+	defaultHealthTimeout   = 5 * time.Second
+	defaultHealthThreshold = 3
+)
+
+// 健康检查失败发送 SIGTERM 后仍未退出时，等待多久再强制结束
+const healthKillGrace = 5 * time.Second
+
+// HTTP 检查读取响应体的上限
+const maxHealthBodySize = 64 * 1024
+
+// HealthCheckConfig 健康检查配置，type 为空时根据 url/address/command 推断
+type HealthCheckConfig struct {
+	Type         string `json:"type,omitempty" yaml:"type,omitempty"` // http、tcp 或 exec
+	URL          string `json:"url,omitempty" yaml:"url,omitempty"`
+	ExpectStatus int    `json:"expect_status,omitempty" yaml:"expect_status,omitempty"` // 默认 200-399
+	ExpectBody   string `json:"expect_body,omitempty" yaml:"expect_body,omitempty"`     // 响应体需匹配的正则
+	Address      string `json:"address,omitempty" yaml:"address,omitempty"`             // 如 127.0.0.1:8080
+	Command      string `json:"command,omitempty" yaml:"command,omitempty"`             // 退出码为 0 视为健康
+	Interval     string `json:"interval,omitempty" yaml:"interval,omitempty"`
+	Timeout      string `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	Threshold    int    `json:"threshold,omitempty" yaml:"threshold,omitempty"`       // 连续失败几次后重启
+	StartPeriod  string `json:"start_period,omitempty" yaml:"start_period,omitempty"` // 启动后此期间内的失败不计数
+}
+
+// healthSettings 解析后的健康检查配置
+type healthSettings struct {
+	kind        string
+	interval    time.Duration
+	timeout     time.Duration
+	threshold   int
+	startPeriod time.Duration
+	bodyPattern *regexp.Regexp
+}
+
+// settings 校验并解析健康检查配置
+func (c *HealthCheckConfig) settings() (*healthSettings, error) {
+	s := &healthSettings{
+		kind:      c.Type,
+		interval:  defaultHealthInterval,
+		timeout:   defaultHealthTimeout,
+		threshold: defaultHealthThreshold,
+	}
+
+	if s.kind == "" {
+		switch {
+		case c.URL != "":
+			s.kind = "http"
+		case c.Address != "":
+			s.kind = "tcp"
+		case c.Command != "":
+			s.kind = "exec"
+		}
+	}
+
+	switch s.kind {
+	case "http":
+		if c.URL == "" {
+			return nil, fmt.Errorf("health_check.url 不能为空")
+		}
+		if c.ExpectBody != "" {
+			pattern, err := regexp.Compile(c.ExpectBody)
+			if err != nil {
+				return nil, fmt.Errorf("health_check.expect_body 无效: %v", err)
+			}
+			s.bodyPattern = pattern
+		}
+	case "tcp":
+		if c.Address == "" {
+			return nil, fmt.Errorf("health_check.address 不能为空")
+		}
+	case "exec":
+		if c.Command == "" {
+			return nil, fmt.Errorf("health_check.command 不能为空")
+		}
+	case "":
+		return nil, fmt.Errorf("health_check 需要指定 url、address 或 command")
+	default:
+		return nil, fmt.Errorf("不支持的健康检查类型: %s", s.kind)
+	}
+
+	durations := []struct {
+		name   string
+		value  string
+		target *time.Duration
+	}{
+		{"interval", c.Interval, &s.interval},
+		{"timeout", c.Timeout, &s.timeout},
+		{"start_period", c.StartPeriod, &s.startPeriod},
+	}
+	for _, d := range durations {
+		if d.value == "" {
+			continue
+		}
+		duration, err := time.ParseDuration(d.value)
+		if err != nil || duration < 0 {
+			return nil, fmt.Errorf("health_check.%s 无效: %s", d.name, d.value)
+		}
+		*d.target = duration
+	}
+	if s.interval < time.Second {
+		return nil, fmt.Errorf("health_check.interval 不能小于1秒")
+	}
+
+	if c.Threshold < 0 {
+		return nil, fmt.Errorf("health_check.threshold 不能为负数")
+	}
+	if c.Threshold > 0 {
+		s.threshold = c.Threshold
+	}
+	return s, nil
+}
+
+// describe 返回健康检查的简要说明，如 "HTTP GET http://127.0.0.1:8080/health"
+func (c *HealthCheckConfig) describe() string {
+	s, err := c.settings()
+	if err != nil {
+		return err.Error()
+	}
+
+	var target string
+	switch s.kind {
+	case "http":
+		target = "HTTP GET " + c.URL
+	case "tcp":
+		target = "TCP " + c.Address
+	default:
+		target = "exec " + c.Command
+	}
+	return fmt.Sprintf("%s (间隔 %s, 超时 %s, 阈值 %d)", target, s.interval, s.timeout, s.threshold)
+}
+
+// healthLoop 健康检查循环，只检查 online 状态的应用，暂停的应用不检查
+func (pm *ProcessManager) healthLoop() {
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		pm.mutex.RLock()
+		processes := make([]*Process, 0)
+		for _, p := range pm.processes {
+			if p.HealthCheck != nil {
+				processes = append(processes, p)
+			}
+		}
+		pm.mutex.RUnlock()
+
+		for _, p := range processes {
+			p.mutex.Lock()
+			// 已发送 SIGTERM 但进程仍未退出，强制结束
+			if !p.healthFired.IsZero() && p.PID > 0 && now.Sub(p.healthFired) >= healthKillGrace {
+				sendSignal(p.PID, syscall.SIGKILL)
+				p.healthFired = now
+			}
+			due := p.Status == StatusOnline && p.healthFired.IsZero() && !p.healthChecking && !now.Before(p.healthNextCheck)
+			if due {
+				p.healthChecking = true
+			}
+			p.mutex.Unlock()
+
+			if due {
+				go pm.runHealthCheck(p)
+			}
+		}
+	}
+}
+
+// resetHealth 进程启动时重置健康状态，首次检查在一个间隔之后（调用方需持有 p.mutex）
+func resetHealth(p *Process) {
+	p.healthFired = time.Time{}
+	if p.HealthCheck == nil {
+		p.Health = ""
+		return
+	}
+	interval := defaultHealthInterval
+	if settings, err := p.HealthCheck.settings(); err == nil {
+		interval = settings.interval
+	}
+	p.Health = HealthStarting
+	p.HealthFailures = 0
+	p.HealthMessage = ""
+	p.healthNextCheck = time.Now().Add(interval)
+}
+
+// runHealthCheck 执行一次健康检查，连续失败达到阈值后结束进程，由 watchProcess 按崩溃重启
+func (pm *ProcessManager) runHealthCheck(p *Process) {
+	p.mutex.Lock()
+	settings, err := p.HealthCheck.settings()
+	config := *p.HealthCheck
+	startTime := p.StartTime
+	// 环境变量需要读取 env_file 和解析密钥，只在执行命令或展开 url/address 中的变量时构建
+	var env []string
+	var envErr error
+	if config.Command != "" || strings.Contains(config.URL, "$") || strings.Contains(config.Address, "$") {
+		env, envErr = pm.buildEnv(p)
+	}
+	cwd := p.Cwd
+	cred, credErr := p.processCredential()
+	p.mutex.Unlock()
+
+	if err == nil {
+		err = envErr
+	}
+	if err == nil {
+		err = credErr
+	}
+	if err == nil {
+		err = probeHealth(settings, &config, env, cwd, cred)
+	}
+
+	p.mutex.Lock()
+	p.healthChecking = false
+
+	// 检查期间应用已重启或停止，结果作废
+	if p.Status != StatusOnline || !p.StartTime.Equal(startTime) {
+		p.mutex.Unlock()
+		return
+	}
+
+	interval := defaultHealthInterval
+	if settings != nil {
+		interval = settings.interval
+	}
+	p.healthNextCheck = time.Now().Add(interval)
+
+	if err == nil {
+		if p.Health != HealthHealthy {
+			pm.recordEvent(p, "health", "健康检查通过")
+		}
+		p.Health = HealthHealthy
+		p.HealthFailures = 0
+		p.HealthMessage = ""
+		p.mutex.Unlock()
+		return
+	}
+
+	p.HealthMessage = err.Error()
+
+	// 启动宽限期内的失败不计数
+	if settings != nil && time.Since(startTime) < settings.startPeriod {
+		p.mutex.Unlock()
+		return
+	}
+
+	threshold := defaultHealthThreshold
+	if settings != nil {
+		threshold = settings.threshold
+	}
+	p.HealthFailures++
+	pm.recordEvent(p, "health", fmt.Sprintf("健康检查失败 (%d/%d): %v", p.HealthFailures, threshold, err))
+	if p.HealthFailures < threshold {
+		p.mutex.Unlock()
+		return
+	}
+
+	// 与看门狗一样只结束进程，重启计入重启次数并遵守 max_restarts
+	p.Health = HealthUnhealthy
+	p.healthFired = time.Now()
+	pm.recordEvent(p, "health", fmt.Sprintf("连续 %d 次健康检查失败，发送 SIGTERM (PID: %d)", p.HealthFailures, p.PID))
+	if err := sendSignal(p.PID, syscall.SIGTERM); err != nil {
+		pm.recordEvent(p, "health", fmt.Sprintf("发送 SIGTERM 失败: %v", err))
+	}
+	p.mutex.Unlock()
+}
+
+// probeHealth 按配置执行 HTTP、TCP 或 exec 检查，失败时返回原因
+func probeHealth(s *healthSettings, c *HealthCheckConfig, env []string, cwd string, cred *credential) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	switch s.kind {
+	case "http":
+		url, err := expandVars(c.URL, envLookup(env))
+		if err != nil {
+			return fmt.Errorf("展开 url 失败: %v", err)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if c.ExpectStatus != 0 && resp.StatusCode != c.ExpectStatus {
+			return fmt.Errorf("HTTP 状态码 %d，期望 %d", resp.StatusCode, c.ExpectStatus)
+		}
+		if c.ExpectStatus == 0 && (resp.StatusCode < 200 || resp.StatusCode >= 400) {
+			return fmt.Errorf("HTTP 状态码 %d", resp.StatusCode)
+		}
+		if s.bodyPattern != nil {
+			body, err := io.ReadAll(io.LimitReader(resp.Body, maxHealthBodySize))
+			if err != nil {
+				return fmt.Errorf("读取响应失败: %v", err)
+			}
+			if !s.bodyPattern.Match(body) {
+				return fmt.Errorf("响应内容不匹配 %s", c.ExpectBody)
+			}
+		}
+		return nil
+
+	case "tcp":
+		address, err := expandVars(c.Address, envLookup(env))
+		if err != nil {
+			return fmt.Errorf("展开 address 失败: %v", err)
+		}
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", address)
+		if err != nil {
+			return err
+		}
+		conn.Close()
+		return nil
+
+	default:
+		cmd := shellCommand(ctx, c.Command)
+		cmd.Dir = cwd
+		cmd.Env = env
+		if err := setCredential(cmd, cred); err != nil {
+			return err
+		}
+		output, err := cmd.CombinedOutput()
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("执行超时 (%s)", s.timeout)
+		}
+		if err != nil {
+			message := strings.TrimSpace(string(output))
+			if len(message) > 200 {
+				message = message[:200] + "..."
+			}
+			if message != "" {
+				return fmt.Errorf("%v: %s", err, message)
+			}
+			return err
+		}
+		return nil
+	}
+}
+
+// envLookup 返回在环境变量列表中查找变量的函数，同名变量以最后一个为准
+func envLookup(env []string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		for i := len(env) - 1; i >= 0; i-- {
+			if name, value, _ := strings.Cut(env[i], "="); name == key {
+				return value, true
+			}
+		}
+		return "", false
+	}
+}
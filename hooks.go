@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+// 钩子默认超时时间
+const defaultHookTimeout = 30 * time.Second
+
+// runHook 在应用的工作目录和环境中执行生命周期钩子
+// 调用方需持有 p.mutex，钩子执行期间会释放锁，返回后调用方需重新检查进程状态
+func (pm *ProcessManager) runHook(p *Process, name, command string, stdout, stderr io.Writer) error {
+	if command == "" {
+		return nil
+	}
+
+	timeout := p.HookTimeout
+	if timeout == 0 {
+		timeout = defaultHookTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := shellCommand(ctx, command)
+	cmd.Dir = p.Cwd
+	env, err := pm.buildEnv(p)
+	if err != nil {
+		return fmt.Errorf("%s 钩子失败: %v", name, err)
+	}
+	cmd.Env = env
+
+	// 钩子与应用以相同的用户运行
+	cred, err := p.processCredential()
+	if err != nil {
+		return fmt.Errorf("%s 钩子失败: %v", name, err)
+	}
+	if err := setCredential(cmd, cred); err != nil {
+		return fmt.Errorf("%s 钩子失败: %v", name, err)
+	}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	fmt.Fprintf(stdout, "[%s] 执行 %s 钩子: %s\n", time.Now().Format("2006-01-02 15:04:05"), name, command)
+
+	// 钩子可能耗时较长（如数据库迁移），执行期间不阻塞对该应用的查询和其他操作
+	startTime := time.Now()
+	p.mutex.Unlock()
+	err = cmd.Run()
+	p.mutex.Lock()
+	if ctx.Err() == context.DeadlineExceeded {
+		err = fmt.Errorf("执行超时 (%s)", timeout)
+	}
+
+	if err != nil {
+		pm.recordEvent(p, "hook", fmt.Sprintf("%s 钩子失败: %v", name, err))
+		return fmt.Errorf("%s 钩子失败: %v", name, err)
+	}
+
+	pm.recordEvent(p, "hook", fmt.Sprintf("%s 钩子执行成功 (耗时 %s)", name, time.Since(startTime).Round(time.Millisecond)))
+	return nil
+}
+
+// openHookLogs 以追加方式打开应用日志，供没有常驻日志文件的任务执行停止钩子，失败时返回 nil
+func openHookLogs(p *Process) (*os.File, *os.File) {
+	os.MkdirAll(filepath.Dir(p.LogFile), 0755)
+	os.MkdirAll(filepath.Dir(p.ErrorFile), 0755)
+	logFile, err := os.OpenFile(p.LogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, nil
+	}
+	errorFile, err := os.OpenFile(p.ErrorFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		logFile.Close()
+		return nil, nil
+	}
+	return logFile, errorFile
+}
+
+// shellCommand 创建通过系统 shell 执行的命令
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "/bin/sh", "-c", command)
+}
+
+// appShellCommand 通过 shell 执行应用的 command，args 作为位置参数 $1、$2...（Windows 下追加到命令行）
+func appShellCommand(ctx context.Context, command, name string, args []string) *exec.Cmd {
+	cmd := shellCommand(ctx, command)
+	if len(args) > 0 {
+		if runtime.GOOS != "windows" {
+			cmd.Args = append(cmd.Args, name)
+		}
+		cmd.Args = append(cmd.Args, args...)
+	}
+	return cmd
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// runtimeFields 在运行时展开的字段，加载配置时不展开：
+// shell 命令由 /bin/sh 使用应用的环境变量展开，健康检查的地址在检查时使用应用的环境变量展开
+var runtimeFields = map[string]bool{
+	"command":              true,
+	"pre_start":            true,
+	"post_start":           true,
+	"pre_stop":             true,
+	"post_stop":            true,
+	"health_check.command": true,
+	"health_check.url":     true,
+	"health_check.address": true,
+}
+
+// interpolateConfig 展开配置文件字符串字段中的 ${VAR} 和 ${VAR:-default}
+// 除调用方的环境变量外，还支持内置变量 CONFIG_DIR、HOME、HOSTNAME 和 INSTANCE
+func interpolateConfig(config *Config, configPath string) error {
+	configDir, err := filepath.Abs(filepath.Dir(configPath))
+	if err != nil {
+		return err
+	}
+
+	for i := range config.Apps {
+		app := &config.Apps[i]
+		name := app.Name
+
+		// 目前每个应用只运行一个实例，实例编号固定为 0
+		builtins := map[string]string{
+			"CONFIG_DIR": configDir,
+			"INSTANCE":   strconv.Itoa(0),
+		}
+		lookup := func(key string) (string, bool) {
+			if value, exists := builtins[key]; exists {
+				return value, true
+			}
+			if value, exists := os.LookupEnv(key); exists {
+				return value, true
+			}
+			switch key {
+			case "HOME":
+				if home, err := os.UserHomeDir(); err == nil {
+					return home, true
+				}
+			case "HOSTNAME":
+				if hostname, err := os.Hostname(); err == nil {
+					return hostname, true
+				}
+			}
+			return "", false
+		}
+
+		if err := interpolateValue(reflect.ValueOf(app).Elem(), "", lookup); err != nil {
+			return fmt.Errorf("应用 '%s': %v", name, err)
+		}
+	}
+	return nil
+}
+
+// interpolateValue 递归展开结构体、切片和 map 中的字符串，path 为用于错误信息的字段路径
+func interpolateValue(v reflect.Value, path string, lookup func(string) (string, bool)) error {
+	switch v.Kind() {
+	case reflect.String:
+		expanded, err := expandVars(v.String(), lookup)
+		if err != nil {
+			return fmt.Errorf("字段 %s: %v", path, err)
+		}
+		v.SetString(expanded)
+
+	case reflect.Ptr:
+		if !v.IsNil() {
+			return interpolateValue(v.Elem(), path, lookup)
+		}
+
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			if name == "" || name == "-" {
+				name = field.Name
+			}
+			if path != "" {
+				name = path + "." + name
+			}
+			if runtimeFields[name] {
+				continue
+			}
+			if err := interpolateValue(v.Field(i), name, lookup); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			if err := interpolateValue(v.Index(i), fmt.Sprintf("%s[%d]", path, i), lookup); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Map:
+		// map 的值不可寻址，展开副本后写回
+		for _, key := range v.MapKeys() {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(v.MapIndex(key))
+			if err := interpolateValue(elem, fmt.Sprintf("%s.%v", path, key), lookup); err != nil {
+				return err
+			}
+			v.SetMapIndex(key, elem)
+		}
+	}
+	return nil
+}
+
+// expandVars 展开字符串中的 ${VAR} 和 ${VAR:-default}，$${ 表示字面量 ${
+// 变量未定义且没有默认值时返回错误，默认值在变量未定义或为空时使用
+func expandVars(s string, lookup func(string) (string, bool)) (string, error) {
+	var b strings.Builder
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			b.WriteString(s)
+			return b.String(), nil
+		}
+
+		// $${ 转义
+		if start > 0 && s[start-1] == '$' {
+			b.WriteString(s[:start-1] + "${")
+			s = s[start+2:]
+			continue
+		}
+
+		end := strings.Index(s[start:], "}")
+		if end < 0 {
+			return "", fmt.Errorf("'%s' 缺少结束的 }", s[start:])
+		}
+		expr := s[start+2 : start+end]
+		b.WriteString(s[:start])
+		s = s[start+end+1:]
+
+		name, def, hasDefault := strings.Cut(expr, ":-")
+		if !isVarName(name) {
+			return "", fmt.Errorf("无效的变量名: ${%s}", expr)
+		}
+
+		value, exists := lookup(name)
+		switch {
+		case exists && (value != "" || !hasDefault):
+			b.WriteString(value)
+		case hasDefault:
+			b.WriteString(def)
+		default:
+			return "", fmt.Errorf("未定义的变量: %s (可使用 ${%s:-默认值})", name, name)
+		}
+	}
+}
+
+// isVarName 检查是否是合法的变量名
+func isVarName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		if r == '_' || (r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z') || (i > 0 && r >= '0' && r <= '9') {
+			continue
+		}
+		return false
+	}
+	return true
+}
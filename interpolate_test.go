@@ -0,0 +1,55 @@
+package main
+
+import "testing"
+
+func TestExpandVars(t *testing.T) {
+	vars := map[string]string{"A": "1", "PORT": "8080", "EMPTY": ""}
+	lookup := func(key string) (string, bool) {
+		value, exists := vars[key]
+		return value, exists
+	}
+
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"", "", false},
+		{"plain", "plain", false},
+		{"${A}", "1", false},
+		{"x${A}y${PORT}", "x1y8080", false},
+		{"localhost:${PORT}", "localhost:8080", false},
+		{"${EMPTY}", "", false},
+		{"${A:-d}", "1", false},
+		{"${MISSING:-def}", "def", false},
+		{"${EMPTY:-def}", "def", false},
+		{"${MISSING:-}", "", false},
+		{"${MISSING:-a b}", "a b", false},
+		{"${_A1:-x}", "x", false},
+
+		// $VAR 不展开，$${ 表示字面量 ${
+		{"$A", "$A", false},
+		{"$${A}", "${A}", false},
+		{"$${A}${A}", "${A}1", false},
+		{"a$b}", "a$b}", false},
+
+		{"${MISSING}", "", true},
+		{"${A", "", true},
+		{"x${", "", true},
+		{"${}", "", true},
+		{"${1A}", "", true},
+		{"${A-B}", "", true},
+		{"${A B}", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := expandVars(tt.input, lookup)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("expandVars(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("expandVars(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
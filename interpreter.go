@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 守护进程设置文件名，位于数据目录下
+const settingsFileName = "settings.yaml"
+
+// 不使用解释器、直接执行脚本
+const interpreterNone = "none"
+
+// defaultInterpreters 默认的扩展名到解释器映射
+var defaultInterpreters = map[string]string{
+	".js":  "node",
+	".mjs": "node",
+	".cjs": "node",
+	".ts":  "node",
+	".py":  "python",
+	".go":  "go run",
+}
+
+// Settings 守护进程设置
+type Settings struct {
+	// Interpreters 扩展名到解释器命令的映射，覆盖默认值，如 ".py": "python3"
+	Interpreters map[string]string `json:"interpreters,omitempty" yaml:"interpreters,omitempty"`
+}
+
+// loadSettings 读取守护进程设置文件，文件不存在时返回空设置
+func (pm *ProcessManager) loadSettings() (*Settings, error) {
+	settings := &Settings{}
+
+	data, err := os.ReadFile(filepath.Join(pm.dataDir, settingsFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return settings, nil
+		}
+		return nil, fmt.Errorf("读取设置文件失败: %v", err)
+	}
+
+	if err := yaml.Unmarshal(data, settings); err != nil {
+		return nil, fmt.Errorf("解析设置文件失败: %v", err)
+	}
+	return settings, nil
+}
+
+// resolveInterpreter 确定运行脚本的解释器及其参数，返回空字符串表示直接执行
+// 优先级: 应用配置的 interpreter > 脚本的 shebang 行 > 设置文件中的扩展名映射 > 默认映射
+func (pm *ProcessManager) resolveInterpreter(p *Process) (string, []string, error) {
+	if p.Interpreter == interpreterNone || p.Command != "" {
+		return "", nil, nil
+	}
+	if p.Interpreter != "" {
+		return p.Interpreter, p.InterpreterArgs, nil
+	}
+
+	if name, args := readShebang(p.scriptPath()); name != "" {
+		return name, append(args, p.InterpreterArgs...), nil
+	}
+
+	settings, err := pm.loadSettings()
+	if err != nil {
+		return "", nil, err
+	}
+
+	ext := strings.ToLower(filepath.Ext(p.Script))
+	command, exists := settings.Interpreters[ext]
+	if !exists {
+		command, exists = defaultInterpreters[ext]
+	}
+	if !exists || command == interpreterNone {
+		return "", nil, nil
+	}
+
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return "", nil, fmt.Errorf("扩展名 %s 的解释器为空", ext)
+	}
+	return fields[0], append(fields[1:], p.InterpreterArgs...), nil
+}
+
+// scriptPath 返回脚本的完整路径，相对路径基于工作目录
+func (p *Process) scriptPath() string {
+	if filepath.IsAbs(p.Script) || p.Cwd == "" {
+		return p.Script
+	}
+	return filepath.Join(p.Cwd, p.Script)
+}
+
+// readShebang 解析脚本首行的 shebang，如 "#!/usr/bin/env -S bun run"
+func readShebang(path string) (string, []string) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", nil
+	}
+	defer file.Close()
+
+	line, err := bufio.NewReader(file).ReadString('\n')
+	if err != nil && line == "" {
+		return "", nil
+	}
+	if !strings.HasPrefix(line, "#!") {
+		return "", nil
+	}
+
+	fields := strings.Fields(strings.TrimPrefix(line, "#!"))
+	if len(fields) == 0 {
+		return "", nil
+	}
+
+	// /usr/bin/env 只负责在 PATH 中查找解释器
+	if filepath.Base(fields[0]) == "env" {
+		fields = fields[1:]
+		if len(fields) > 0 && fields[0] == "-S" {
+			fields = fields[1:]
+		}
+		if len(fields) == 0 {
+			return "", nil
+		}
+	}
+
+	return fields[0], fields[1:]
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadShebang(t *testing.T) {
+	tests := []struct {
+		content  string
+		wantName string
+		wantArgs []string
+	}{
+		{"#!/bin/sh\necho hi\n", "/bin/sh", []string{}},
+		{"#!/usr/bin/python3 -u\n", "/usr/bin/python3", []string{"-u"}},
+		{"#! /bin/bash -e -x\n", "/bin/bash", []string{"-e", "-x"}},
+		{"#!/usr/bin/env node\n", "node", []string{}},
+		{"#!/usr/bin/env -S bun run\n", "bun", []string{"run"}},
+		{"#!/usr/bin/env python3\r\n", "python3", []string{}},
+		{"#!/bin/sh", "/bin/sh", []string{}},
+
+		{"", "", nil},
+		{"console.log(1)\n", "", nil},
+		{"\n#!/bin/sh\n", "", nil},
+		{"#!\n", "", nil},
+		{"#!/usr/bin/env\n", "", nil},
+		{"#!/usr/bin/env -S\n", "", nil},
+	}
+
+	dir := t.TempDir()
+	for i, tt := range tests {
+		path := filepath.Join(dir, "script")
+		if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		name, args := readShebang(path)
+		if name != tt.wantName || !reflect.DeepEqual(args, tt.wantArgs) {
+			t.Errorf("%d: readShebang(%q) = %q, %q, want %q, %q", i, tt.content, name, args, tt.wantName, tt.wantArgs)
+		}
+	}
+
+	if name, args := readShebang(filepath.Join(dir, "missing")); name != "" || args != nil {
+		t.Errorf("readShebang(missing) = %q, %q, want empty", name, args)
+	}
+}
+
+func TestResolveInterpreter(t *testing.T) {
+	dir := t.TempDir()
+	pm := &ProcessManager{dataDir: dir}
+	settings := "interpreters:\n  .py: python3 -u\n  .rb: ruby\n  .js: none\n"
+	if err := os.WriteFile(filepath.Join(dir, settingsFileName), []byte(settings), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "tool.py"), []byte("#!/usr/bin/env -S uv run\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		process  *Process
+		wantName string
+		wantArgs []string
+	}{
+		{"explicit interpreter", &Process{Script: "app.py", Interpreter: "pypy", InterpreterArgs: []string{"-O"}}, "pypy", []string{"-O"}},
+		{"interpreter none", &Process{Script: "app.py", Interpreter: interpreterNone}, "", nil},
+		{"shell command", &Process{Command: "npm start", Script: "app.js"}, "", nil},
+		{"shebang before extension", &Process{Script: "tool.py", Cwd: dir, InterpreterArgs: []string{"-q"}}, "uv", []string{"run", "-q"}},
+		{"settings override default", &Process{Script: "app.py", Cwd: dir}, "python3", []string{"-u"}},
+		{"settings add extension", &Process{Script: "app.rb", Cwd: dir}, "ruby", []string{}},
+		{"settings disable extension", &Process{Script: "app.js", Cwd: dir}, "", nil},
+		{"default mapping", &Process{Script: "app.TS", Cwd: dir}, "node", []string{}},
+		{"default with args", &Process{Script: "main.go", Cwd: dir}, "go", []string{"run"}},
+		{"unknown extension", &Process{Script: "server", Cwd: dir}, "", nil},
+	}
+
+	for _, tt := range tests {
+		name, args, err := pm.resolveInterpreter(tt.process)
+		if err != nil {
+			t.Errorf("%s: resolveInterpreter error = %v", tt.name, err)
+			continue
+		}
+		if name != tt.wantName || !reflect.DeepEqual(args, tt.wantArgs) {
+			t.Errorf("%s: resolveInterpreter = %q, %q, want %q, %q", tt.name, name, args, tt.wantName, tt.wantArgs)
+		}
+	}
+}
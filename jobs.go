@@ -0,0 +1,349 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// jobRunHandle 正在进行的任务运行
+type jobRunHandle struct {
+	cmd  *exec.Cmd
+	done chan struct{}
+}
+
+// applyJobConfig 解析任务相关配置
+func applyJobConfig(p *Process, config AppConfig) error {
+	if config.Schedule != "" {
+		schedule, err := ParseCron(config.Schedule)
+		if err != nil {
+			return fmt.Errorf("解析调度表达式失败: %v", err)
+		}
+		p.Schedule = config.Schedule
+		p.NextRun = schedule.Next(time.Now())
+	}
+
+	switch OverlapPolicy(config.Overlap) {
+	case OverlapQueue, OverlapAllow:
+		p.Overlap = OverlapPolicy(config.Overlap)
+	default:
+		p.Overlap = OverlapSkip
+	}
+
+	if config.MaxRunTime != "" {
+		duration, err := time.ParseDuration(config.MaxRunTime)
+		if err != nil {
+			return fmt.Errorf("解析最大运行时间失败: %v", err)
+		}
+		p.MaxRunTime = duration
+	}
+
+	p.KeepRuns = config.KeepRuns
+	if p.KeepRuns == 0 {
+		p.KeepRuns = 10
+	}
+
+	return nil
+}
+
+// RunJob 触发一次任务运行，返回是否进入排队
+func (pm *ProcessManager) RunJob(nameOrID string) (*Process, bool, error) {
+	// pre_start 钩子执行期间不持有 pm.mutex
+	pm.mutex.RLock()
+	process := pm.findProcess(nameOrID)
+	pm.mutex.RUnlock()
+	if process == nil {
+		return nil, false, fmt.Errorf("未找到进程: %s", nameOrID)
+	}
+
+	if process.Type != AppTypeJob {
+		return nil, false, fmt.Errorf("进程 '%s' 不是任务类型 (type: job)", process.Name)
+	}
+
+	process.mutex.Lock()
+	queued, err := pm.triggerJob(process, "manual")
+	process.mutex.Unlock()
+
+	pm.saveProcesses()
+	if err != nil {
+		return process, false, fmt.Errorf("运行任务失败: %v", err)
+	}
+	return process, queued, nil
+}
+
+// triggerJob 按重叠策略触发任务运行（调用方需持有 p.mutex）
+func (pm *ProcessManager) triggerJob(p *Process, trigger string) (bool, error) {
+	if p.Status == StatusStopping {
+		return false, fmt.Errorf("任务 '%s' 正在停止", p.Name)
+	}
+
+	// 正在执行 pre_start 钩子的运行同样计入
+	if len(p.activeRuns) > 0 || p.starting > 0 {
+		switch p.Overlap {
+		case OverlapQueue:
+			p.queuedRuns++
+			return true, nil
+		case OverlapAllow:
+			// 允许并发运行
+		default:
+			return false, fmt.Errorf("任务 '%s' 正在运行", p.Name)
+		}
+	}
+
+	return false, pm.startJobRun(p, trigger)
+}
+
+// startJobRun 启动一次任务运行（调用方需持有 p.mutex）
+func (pm *ProcessManager) startJobRun(p *Process, trigger string) error {
+	p.RunCount++
+	run := &JobRun{
+		ID:        p.RunCount,
+		Trigger:   trigger,
+		StartTime: time.Now(),
+	}
+
+	// 启动失败时都通过 completeJobRun 记录结果并更新任务状态
+	runDir, err := pm.appDataDir("jobs", p.Name)
+	if err != nil {
+		pm.completeJobRun(p, run, -1, err.Error())
+		return err
+	}
+	run.LogFile = filepath.Join(runDir, fmt.Sprintf("%d.log", run.ID))
+	run.ErrorFile = filepath.Join(runDir, fmt.Sprintf("%d-error.log", run.ID))
+
+	cred, err := p.processCredential()
+	if err != nil {
+		pm.completeJobRun(p, run, -1, err.Error())
+		return err
+	}
+
+	// 输出同时写入应用日志和本次运行的日志
+	os.MkdirAll(runDir, 0755)
+	var files []*os.File
+	for _, path := range []string{p.LogFile, run.LogFile, p.ErrorFile, run.ErrorFile} {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			closeFiles(files)
+			pm.completeJobRun(p, run, -1, err.Error())
+			return fmt.Errorf("创建日志文件失败: %v", err)
+		}
+		files = append(files, file)
+		chownFile(path, cred)
+	}
+
+	stdout := io.MultiWriter(files[0], files[1])
+	stderr := io.MultiWriter(files[2], files[3])
+
+	// 执行 pre_start 钩子，失败则本次运行中止，钩子执行期间任务可能已被停止或删除
+	// 随后的编译同样不持有锁，编译结束前仍计入正在启动的运行
+	stops := p.stops
+	p.starting++
+	err = pm.runHook(p, "pre_start", p.PreStart, stdout, stderr)
+	if err == nil && (p.stops != stops || p.deleted) {
+		err = fmt.Errorf("任务 '%s' 已停止", p.Name)
+	}
+	if err != nil {
+		p.starting--
+		closeFiles(files)
+		pm.completeJobRun(p, run, -1, err.Error())
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if p.MaxRunTime > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), p.MaxRunTime)
+	}
+
+	cmd, err := pm.buildCommand(ctx, p, stderr)
+	p.starting--
+	if err == nil {
+		cmd.Stdout = stdout
+		cmd.Stderr = stderr
+		err = cmd.Start()
+	}
+	if err != nil {
+		cancel()
+		closeFiles(files)
+		pm.completeJobRun(p, run, -1, err.Error())
+		if p.Status == StatusErrored {
+			p.Status = startFailedStatus(err)
+		}
+		return fmt.Errorf("启动命令失败: %v", err)
+	}
+
+	handle := &jobRunHandle{cmd: cmd, done: make(chan struct{})}
+	if p.activeRuns == nil {
+		p.activeRuns = make(map[int]*jobRunHandle)
+	}
+	p.activeRuns[run.ID] = handle
+
+	p.PID = cmd.Process.Pid
+	p.Status = StatusOnline
+	p.StartTime = run.StartTime
+	recordProcessIdentity(p)
+
+	// 保存PID文件
+	pidFile := filepath.Join(pm.dataDir, "pids", fmt.Sprintf("%s.pid", p.Name))
+	os.WriteFile(pidFile, []byte(strconv.Itoa(p.PID)), 0644)
+
+	pm.recordEvent(p, "start", fmt.Sprintf("任务第 %d 次运行已启动 (PID: %d, 触发: %s)", run.ID, p.PID, trigger))
+
+	go pm.waitJobRun(p, run, handle, ctx, cancel, files)
+
+	return nil
+}
+
+// waitJobRun 等待任务运行结束并记录结果
+func (pm *ProcessManager) waitJobRun(p *Process, run *JobRun, handle *jobRunHandle, ctx context.Context, cancel context.CancelFunc, files []*os.File) {
+	err := handle.cmd.Wait()
+	timedOut := ctx.Err() == context.DeadlineExceeded
+	cancel()
+	close(handle.done)
+	closeFiles(files)
+
+	p.mutex.Lock()
+	errMsg := ""
+	if timedOut {
+		errMsg = fmt.Sprintf("超过最大运行时间 (%s)，已终止", p.MaxRunTime)
+	} else if oomKills := readOOMKills(p.CgroupPath); err != nil && oomKills > p.oomKills {
+		p.oomKills = oomKills
+		errMsg = fmt.Sprintf("内存超出 cgroup 限制，被 OOM killer 终止: %v", err)
+	} else if err != nil {
+		errMsg = err.Error()
+	}
+
+	delete(p.activeRuns, run.ID)
+	// 最后一次运行结束后删除 cgroup，下次运行时重新创建
+	if len(p.activeRuns) == 0 {
+		pm.cleanupCgroup(p)
+	}
+	pm.completeJobRun(p, run, exitCodeOf(handle.cmd, err), errMsg)
+	p.mutex.Unlock()
+
+	pm.saveProcesses()
+}
+
+// completeJobRun 记录运行结果并更新任务状态（调用方需持有 p.mutex）
+func (pm *ProcessManager) completeJobRun(p *Process, run *JobRun, exitCode int, errMsg string) {
+	run.EndTime = time.Now()
+	run.Duration = run.EndTime.Sub(run.StartTime)
+	run.ExitCode = exitCode
+	run.Error = errMsg
+
+	// 保留最近 KeepRuns 次运行结果及其日志
+	p.Runs = append(p.Runs, run)
+	for p.KeepRuns > 0 && len(p.Runs) > p.KeepRuns {
+		os.Remove(p.Runs[0].LogFile)
+		os.Remove(p.Runs[0].ErrorFile)
+		p.Runs = p.Runs[1:]
+	}
+
+	// 任务被手动停止时保持停止状态
+	if p.Status == StatusStopping || p.Status == StatusStopped {
+		return
+	}
+
+	// 还有其他运行在进行中
+	for _, handle := range p.activeRuns {
+		p.PID = handle.cmd.Process.Pid
+		return
+	}
+
+	// 处理排队的运行，启动失败时 startJobRun 已记录结果并更新了状态，不能再覆盖
+	if p.queuedRuns > 0 {
+		p.queuedRuns--
+		pm.startJobRun(p, "queue")
+		return
+	}
+
+	p.PID = 0
+	if exitCode == 0 {
+		p.Status = StatusOneTime
+	} else {
+		p.Status = StatusErrored
+	}
+
+	pidFile := filepath.Join(pm.dataDir, "pids", fmt.Sprintf("%s.pid", p.Name))
+	os.Remove(pidFile)
+}
+
+// idleJobStatus 返回任务空闲时的状态，由最近一次运行的结果决定
+func idleJobStatus(p *Process) ProcessStatus {
+	if n := len(p.Runs); n > 0 && p.Runs[n-1].ExitCode != 0 {
+		return StatusErrored
+	}
+	return StatusOneTime
+}
+
+// stopJobRuns 终止任务所有正在进行的运行（调用方需持有 p.mutex）
+func (pm *ProcessManager) stopJobRuns(p *Process) {
+	p.queuedRuns = 0
+
+	// 运行在独立的进程组中，与应用一样向整个进程组发送信号，shell 派生的子进程一并退出
+	kill := func(h *jobRunHandle) {
+		if sendSignal(h.cmd.Process.Pid, syscall.SIGKILL) != nil {
+			h.cmd.Process.Kill()
+		}
+	}
+	for _, handle := range p.activeRuns {
+		if sendSignal(handle.cmd.Process.Pid, syscall.SIGTERM) != nil {
+			kill(handle)
+			continue
+		}
+
+		// 5秒内未退出则强制杀死
+		go func(h *jobRunHandle) {
+			select {
+			case <-h.done:
+			case <-time.After(5 * time.Second):
+				kill(h)
+			}
+		}(handle)
+	}
+}
+
+// writeJobLog 向任务的应用日志追加一行
+func (pm *ProcessManager) writeJobLog(p *Process, msg string) {
+	file, err := os.OpenFile(p.LogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	file.WriteString(fmt.Sprintf("[%s] %s\n", time.Now().Format("2006-01-02 15:04:05"), msg))
+}
+
+// lastRun 返回最近一次结束的运行结果
+func (p *Process) lastRun() *JobRun {
+	if len(p.Runs) == 0 {
+		return nil
+	}
+	return p.Runs[len(p.Runs)-1]
+}
+
+// exitCodeOf 获取进程退出码，无法获取时返回 -1
+func exitCodeOf(cmd *exec.Cmd, err error) int {
+	if cmd != nil && cmd.ProcessState != nil {
+		return cmd.ProcessState.ExitCode()
+	}
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return exitErr.ExitCode()
+	}
+	if err != nil {
+		return -1
+	}
+	return 0
+}
+
+// closeFiles 关闭一组文件
+func closeFiles(files []*os.File) {
+	for _, file := range files {
+		file.Close()
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// 守护进程以此参数重新执行自身，设置资源限制后再执行应用
+const limitShimCommand = "__exec-with-limits"
+
+// 不限制
+const rlimitInfinity = ^uint64(0)
+
+// LimitValue 资源限制值，支持数字、"软限制:硬限制" 和 unlimited
+type LimitValue string
+
+// UnmarshalJSON 允许在 JSON 配置中直接使用数字
+func (v *LimitValue) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*v = LimitValue(s)
+		return nil
+	}
+
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("无效的资源限制值: %s", data)
+	}
+	*v = LimitValue(n.String())
+	return nil
+}
+
+// ResourceLimits 进程资源限制 (rlimit)
+type ResourceLimits struct {
+	NoFile  LimitValue `json:"nofile,omitempty" yaml:"nofile,omitempty"`
+	NProc   LimitValue `json:"nproc,omitempty" yaml:"nproc,omitempty"`
+	Core    LimitValue `json:"core,omitempty" yaml:"core,omitempty"`
+	AS      LimitValue `json:"as,omitempty" yaml:"as,omitempty"`
+	Stack   LimitValue `json:"stack,omitempty" yaml:"stack,omitempty"`
+	MemLock LimitValue `json:"memlock,omitempty" yaml:"memlock,omitempty"`
+}
+
+// limitEntry 单项资源限制
+type limitEntry struct {
+	name string
+	soft uint64
+	hard uint64
+}
+
+// entries 解析所有已设置的资源限制
+func (l *ResourceLimits) entries() ([]limitEntry, error) {
+	if l == nil {
+		return nil, nil
+	}
+
+	fields := []struct {
+		name  string
+		value LimitValue
+	}{
+		{"nofile", l.NoFile},
+		{"nproc", l.NProc},
+		{"core", l.Core},
+		{"as", l.AS},
+		{"stack", l.Stack},
+		{"memlock", l.MemLock},
+	}
+
+	var entries []limitEntry
+	for _, field := range fields {
+		if field.value == "" {
+			continue
+		}
+		soft, hard, err := parseLimitValue(string(field.value))
+		if err != nil {
+			return nil, fmt.Errorf("limits.%s: %v", field.name, err)
+		}
+		entries = append(entries, limitEntry{name: field.name, soft: soft, hard: hard})
+	}
+	return entries, nil
+}
+
+// parseLimitValue 解析 "1024"、"1024:4096" 或 "unlimited"，只写一个值时软硬限制相同
+func parseLimitValue(value string) (uint64, uint64, error) {
+	parts := strings.SplitN(value, ":", 2)
+	soft, err := parseLimitNumber(parts[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	hard := soft
+	if len(parts) == 2 {
+		if hard, err = parseLimitNumber(parts[1]); err != nil {
+			return 0, 0, err
+		}
+	}
+	if soft > hard {
+		return 0, 0, fmt.Errorf("软限制 %s 大于硬限制", value)
+	}
+	return soft, hard, nil
+}
+
+// parseLimitNumber 解析单个限制值
+func parseLimitNumber(value string) (uint64, error) {
+	value = strings.TrimSpace(value)
+	if value == "unlimited" || value == "infinity" {
+		return rlimitInfinity, nil
+	}
+	n, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("无效的资源限制值: %s", value)
+	}
+	return n, nil
+}
+
+// formatLimitSpec 将资源限制编码为传给 shim 的参数，如 "nofile=1024:4096,core=0:0"
+func formatLimitSpec(entries []limitEntry) string {
+	parts := make([]string, 0, len(entries))
+	for _, e := range entries {
+		parts = append(parts, fmt.Sprintf("%s=%d:%d", e.name, e.soft, e.hard))
+	}
+	return strings.Join(parts, ",")
+}
+
+// 在 /proc/<pid>/limits 中对应的行
+var procLimitNames = map[string]string{
+	"Max open files":     "nofile",
+	"Max processes":      "nproc",
+	"Max core file size": "core",
+	"Max address space":  "as",
+	"Max stack size":     "stack",
+	"Max locked memory":  "memlock",
+}
+
+// readProcLimits 读取进程实际生效的资源限制，返回 名称 -> "软限制 / 硬限制"
+func readProcLimits(pid int) (map[string]string, error) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/limits", pid))
+	if err != nil {
+		return nil, err
+	}
+
+	limits := make(map[string]string)
+	for _, line := range strings.Split(string(data), "\n") {
+		for prefix, name := range procLimitNames {
+			if !strings.HasPrefix(line, prefix) {
+				continue
+			}
+			fields := strings.Fields(strings.TrimPrefix(line, prefix))
+			if len(fields) >= 2 {
+				limits[name] = fields[0] + " / " + fields[1]
+			}
+		}
+	}
+	return limits, nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParseLimitValue(t *testing.T) {
+	tests := []struct {
+		value    string
+		wantSoft uint64
+		wantHard uint64
+		wantErr  bool
+	}{
+		{"1024", 1024, 1024, false},
+		{"0", 0, 0, false},
+		{"1024:4096", 1024, 4096, false},
+		{" 1024 : 4096 ", 1024, 4096, false},
+		{"unlimited", rlimitInfinity, rlimitInfinity, false},
+		{"infinity", rlimitInfinity, rlimitInfinity, false},
+		{"1024:unlimited", 1024, rlimitInfinity, false},
+		{"18446744073709551615", rlimitInfinity, rlimitInfinity, false},
+
+		{"", 0, 0, true},
+		{"-1", 0, 0, true},
+		{"1k", 0, 0, true},
+		{"1024:", 0, 0, true},
+		{":4096", 0, 0, true},
+		{"1:2:3", 0, 0, true},
+		{"4096:1024", 0, 0, true},
+		{"unlimited:1024", 0, 0, true},
+		{"18446744073709551616", 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		soft, hard, err := parseLimitValue(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseLimitValue(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && (soft != tt.wantSoft || hard != tt.wantHard) {
+			t.Errorf("parseLimitValue(%q) = %d, %d, want %d, %d", tt.value, soft, hard, tt.wantSoft, tt.wantHard)
+		}
+	}
+}
+
+func TestResourceLimitsEntries(t *testing.T) {
+	tests := []struct {
+		config   string
+		want     []limitEntry
+		wantSpec string
+		wantErr  bool
+	}{
+		{`{}`, nil, "", false},
+		{`{"nofile": 65536}`, []limitEntry{{"nofile", 65536, 65536}}, "nofile=65536:65536", false},
+		{`{"nofile": "1024:4096", "core": 0}`, []limitEntry{
+			{"nofile", 1024, 4096},
+			{"core", 0, 0},
+		}, "nofile=1024:4096,core=0:0", false},
+		{`{"memlock": "unlimited"}`, []limitEntry{{"memlock", rlimitInfinity, rlimitInfinity}}, "memlock=18446744073709551615:18446744073709551615", false},
+
+		{`{"nproc": "lots"}`, nil, "", true},
+		{`{"stack": "2:1"}`, nil, "", true},
+	}
+
+	for _, tt := range tests {
+		var limits ResourceLimits
+		if err := json.Unmarshal([]byte(tt.config), &limits); err != nil {
+			t.Fatalf("json.Unmarshal(%s) error = %v", tt.config, err)
+		}
+		entries, err := limits.entries()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("entries(%s) error = %v, wantErr %v", tt.config, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if !reflect.DeepEqual(entries, tt.want) {
+			t.Errorf("entries(%s) = %+v, want %+v", tt.config, entries, tt.want)
+		}
+		if spec := formatLimitSpec(entries); spec != tt.wantSpec {
+			t.Errorf("formatLimitSpec(%s) = %q, want %q", tt.config, spec, tt.wantSpec)
+		}
+	}
+
+	var nilLimits *ResourceLimits
+	if entries, err := nilLimits.entries(); entries != nil || err != nil {
+		t.Errorf("nil entries() = %v, %v, want nil, nil", entries, err)
+	}
+
+	var limits ResourceLimits
+	if err := json.Unmarshal([]byte(`{"nofile": true}`), &limits); err == nil {
+		t.Errorf("json.Unmarshal(bool) error = nil, want error")
+	}
+}
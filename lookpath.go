@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// isBareCommand 判断是否是不含路径分隔符的命令名，如 npm
+func isBareCommand(name string) bool {
+	return name != "" && !strings.ContainsRune(name, '/') && !strings.ContainsRune(name, filepath.Separator)
+}
+
+// lookPathIn 在指定的 PATH 中查找命令，PATH 中的相对目录基于 cwd
+// 含路径分隔符的命令原样返回；找不到时返回的错误列出所搜索的 PATH
+func lookPathIn(name, path, cwd string) (string, error) {
+	if !isBareCommand(name) {
+		return name, nil
+	}
+
+	for _, dir := range filepath.SplitList(path) {
+		if dir == "" {
+			dir = "."
+		}
+		if !filepath.IsAbs(dir) && cwd != "" {
+			dir = filepath.Join(cwd, dir)
+		}
+		for _, candidate := range executableCandidates(filepath.Join(dir, name)) {
+			if isExecutableFile(candidate) {
+				return candidate, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("在 PATH 中未找到命令 '%s' (已搜索: %s)", name, path)
+}
+
+// executableCandidates 返回可能的可执行文件路径，Windows 下按 PATHEXT 补全扩展名
+func executableCandidates(path string) []string {
+	if runtime.GOOS != "windows" || filepath.Ext(path) != "" {
+		return []string{path}
+	}
+
+	pathext := os.Getenv("PATHEXT")
+	if pathext == "" {
+		pathext = ".com;.exe;.bat;.cmd"
+	}
+	var candidates []string
+	for _, ext := range strings.Split(pathext, ";") {
+		if ext != "" {
+			candidates = append(candidates, path+strings.ToLower(ext))
+		}
+	}
+	return candidates
+}
+
+// isExecutableFile 检查路径是否是可执行的普通文件
+func isExecutableFile(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	return runtime.GOOS == "windows" || info.Mode()&0111 != 0
+}
+
+// appPath 返回校验配置时应用使用的 PATH，优先使用应用 env 中的设置
+func appPath(app AppConfig) string {
+	if path, exists := app.Envs[app.EnvProfile]["PATH"]; exists {
+		return path
+	}
+	if path, exists := app.Env["PATH"]; exists {
+		return path
+	}
+	return os.Getenv("PATH")
+}
+
+// envPath 返回环境变量列表中生效的 PATH（同名变量以最后一个为准，Windows 下不区分大小写）
+func envPath(env []string) string {
+	for i := len(env) - 1; i >= 0; i-- {
+		key, value, _ := strings.Cut(env[i], "=")
+		if key == "PATH" || (runtime.GOOS == "windows" && strings.EqualFold(key, "PATH")) {
+			return value
+		}
+	}
+	return ""
+}
@@ -14,6 +14,12 @@ import (
 )
 
 func main() {
+	// 守护进程通过自身设置资源限制后执行应用
+	if len(os.Args) > 1 && os.Args[1] == limitShimCommand {
+		runLimitShim(os.Args[2:])
+		return
+	}
+
 	// 检查是否需要以守护进程模式运行
 	if len(os.Args) > 1 && os.Args[1] == "daemon" {
 		runDaemon()
@@ -44,8 +50,9 @@ func runDaemon() {
 	// 确保退出时删除锁文件
 	defer os.Remove(lockFile)
 
-	// 恢复之前的进程
-	pm.loadProcesses()
+	// 接管或恢复之前的进程
+	pm.initCgroups()
+	pm.recoverProcesses()
 
 	// 设置信号处理
 	sigChan := make(chan os.Signal, 1)
@@ -54,6 +61,13 @@ func runDaemon() {
 	// 启动命令处理循环
 	go pm.commandLoop()
 
+	// 启动定时任务调度
+	go pm.schedulerLoop()
+
+	// 启动健康检查和看门狗
+	go pm.healthLoop()
+	go pm.watchdogLoop()
+
 	// 启动定期保存进程状态
 	go func() {
 		ticker := time.NewTicker(10 * time.Second)
@@ -75,10 +89,11 @@ func runDaemon() {
 	<-sigChan
 	fmt.Println("正在关闭 GoPM2 守护进程...")
 
-	// 优雅关闭所有进程
-	processes := pm.GetProcessList()
-	for _, p := range processes {
-		if p.Status == StatusOnline {
+	// 按依赖关系逆序优雅关闭所有进程
+	processes := sortProcessesByDependency(pm.GetProcessList())
+	for i := len(processes) - 1; i >= 0; i-- {
+		p := processes[i]
+		if p.isRunning() {
 			fmt.Printf("停止进程: %s\n", p.Name)
 			pm.stopProcessInstance(p)
 		}
@@ -103,11 +118,9 @@ func ensureDaemonRunning() {
 		return
 	}
 
-	// 在 Windows 上启动守护进程
+	// 启动守护进程，使其脱离当前终端
 	cmd := exec.Command(executable, "daemon")
-	cmd.SysProcAttr = &syscall.SysProcAttr{
-		CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP,
-	}
+	cmd.SysProcAttr = daemonSysProcAttr()
 	err = cmd.Start()
 	if err != nil {
 		fmt.Printf("启动守护进程失败: %v\n", err)
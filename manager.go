@@ -4,11 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -41,8 +44,53 @@ func NewProcessManager() *ProcessManager {
 
 // StartProcess 启动进程
 func (pm *ProcessManager) StartProcess(config AppConfig) (*Process, error) {
+	// 端口占用情况需要遍历 /proc，在加锁前读取
+	usage := probePort(config.declaredPort())
+
+	// 只在登记进程时持有 pm.mutex，pre_start 钩子和编译可能耗时较长，不阻塞其他命令
 	pm.mutex.Lock()
-	defer pm.mutex.Unlock()
+	process, err := pm.registerProcess(config, usage)
+	pm.mutex.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	// 定时任务等待调度器触发，不立即运行
+	if process.Type == AppTypeJob && process.Schedule != "" {
+		process.mutex.Lock()
+		process.Status = StatusOneTime
+		process.mutex.Unlock()
+		pm.saveProcesses()
+		return process, nil
+	}
+
+	// 启动进程
+	// 启动失败的进程保留在列表中，状态和失败原因一并保存
+	err = pm.startProcessInstance(process)
+	if err != nil {
+		process.mutex.Lock()
+		process.Status = startFailedStatus(err)
+		process.mutex.Unlock()
+		pm.saveProcesses()
+		return process, fmt.Errorf("启动进程失败: %v", err)
+	}
+
+	// 保存进程信息
+	pm.saveProcesses()
+
+	// 如果启用了文件监控，启动文件监控器
+	if process.Watch {
+		go pm.startFileWatcher(process)
+	}
+
+	return process, nil
+}
+
+// registerProcess 校验配置并登记新进程，usage 为加锁前读取的端口占用情况（调用方需持有 pm.mutex）
+func (pm *ProcessManager) registerProcess(config AppConfig, usage portUsage) (*Process, error) {
+	if err := validateAppName(config.Name); err != nil {
+		return nil, err
+	}
 
 	// 检查进程名是否已存在
 	for _, p := range pm.processes {
@@ -51,14 +99,27 @@ func (pm *ProcessManager) StartProcess(config AppConfig) (*Process, error) {
 		}
 	}
 
+	if config.EnvProfile != "" && !hasEnvProfile(config.Envs, config.EnvProfile) {
+		return nil, fmt.Errorf("'%s' 未定义环境配置: %s", config.Name, config.EnvProfile)
+	}
+	if config.HealthCheck != nil {
+		if _, err := config.HealthCheck.settings(); err != nil {
+			return nil, fmt.Errorf("'%s': %v", config.Name, err)
+		}
+	}
+
 	// 创建进程实例
 	process := &Process{
 		ID:          pm.nextID,
 		Name:        config.Name,
 		Script:      config.Script,
+		Command:     config.Command,
 		Args:        config.Args,
 		Cwd:         config.Cwd,
 		Env:         config.Env,
+		EnvFile:     config.EnvFile,
+		Envs:        config.Envs,
+		EnvProfile:  config.EnvProfile,
 		Instances:   config.Instances,
 		Status:      StatusStopped,
 		Watch:       config.Watch,
@@ -66,7 +127,27 @@ func (pm *ProcessManager) StartProcess(config AppConfig) (*Process, error) {
 		MaxRestarts: config.MaxRestarts,
 		LogFile:     config.LogFile,
 		ErrorFile:   config.ErrorFile,
+		Namespace:   config.Namespace,
+		Labels:      config.Labels,
+		DependsOn:   config.DependsOn,
+		PreStart:    config.PreStart,
+		PostStart:   config.PostStart,
+		PreStop:     config.PreStop,
+		PostStop:    config.PostStop,
 		watcherStop: make(chan bool, 1),
+
+		Interpreter:     config.Interpreter,
+		InterpreterArgs: config.InterpreterArgs,
+
+		User:   config.User,
+		Group:  config.Group,
+		Groups: config.Groups,
+		Limits: config.Limits,
+		Cgroup: config.Cgroup,
+
+		HealthCheck:    config.HealthCheck,
+		WatchdogSignal: config.WatchdogSignal,
+		Port:           config.Port,
 	}
 
 	// 设置默认值
@@ -76,6 +157,9 @@ func (pm *ProcessManager) StartProcess(config AppConfig) (*Process, error) {
 	if process.MaxRestarts == 0 {
 		process.MaxRestarts = 15
 	}
+	if process.Namespace == "" {
+		process.Namespace = defaultNamespace
+	}
 	if process.Cwd == "" {
 		process.Cwd, _ = os.Getwd()
 	}
@@ -86,6 +170,13 @@ func (pm *ProcessManager) StartProcess(config AppConfig) (*Process, error) {
 		process.ErrorFile = filepath.Join(pm.dataDir, "logs", fmt.Sprintf("%s-error.log", process.Name))
 	}
 
+	// 设置应用类型
+	if config.Type == string(AppTypeJob) {
+		process.Type = AppTypeJob
+	} else {
+		process.Type = AppTypeService
+	}
+
 	// 设置执行模式
 	if config.ExecMode == "cluster" {
 		process.ExecMode = ExecModeCluster
@@ -104,24 +195,48 @@ func (pm *ProcessManager) StartProcess(config AppConfig) (*Process, error) {
 		process.MinUptime = 1 * time.Second
 	}
 
-	pm.processes[pm.nextID] = process
-	pm.nextID++
+	// 解析钩子超时时间
+	if config.HookTimeout != "" {
+		duration, err := time.ParseDuration(config.HookTimeout)
+		if err == nil {
+			process.HookTimeout = duration
+		}
+	}
 
-	// 启动进程
-	err := pm.startProcessInstance(process)
-	if err != nil {
-		process.Status = StatusErrored
-		return process, fmt.Errorf("启动进程失败: %v", err)
+	if config.Port < 0 || config.Port > 65535 {
+		return nil, fmt.Errorf("port 无效: %d", config.Port)
 	}
 
-	// 保存进程信息
-	pm.saveProcesses()
+	// 解析看门狗超时时间
+	if config.WatchdogTimeout != "" {
+		duration, err := time.ParseDuration(config.WatchdogTimeout)
+		if err != nil || duration < time.Second {
+			return nil, fmt.Errorf("watchdog_timeout 无效或小于1秒: %s", config.WatchdogTimeout)
+		}
+		process.WatchdogTimeout = duration
+	}
+	if config.WatchdogSignal != "" {
+		if _, _, err := parseSignal(config.WatchdogSignal); err != nil {
+			return nil, fmt.Errorf("watchdog_signal: %v", err)
+		}
+	}
 
-	// 如果启用了文件监控，启动文件监控器
-	if process.Watch {
-		go pm.startFileWatcher(process)
+	// 设置任务调度参数
+	if process.Type == AppTypeJob {
+		err := applyJobConfig(process, config)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// 声明的端口已被占用时拒绝启动，避免应用反复崩溃重启
+	if err := pm.checkPortConflict(process, usage); err != nil {
+		return nil, err
 	}
 
+	pm.processes[pm.nextID] = process
+	pm.nextID++
+
 	return process, nil
 }
 
@@ -130,12 +245,31 @@ func (pm *ProcessManager) startProcessInstance(p *Process) error {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
-	// 创建日志文件
+	// 任务的每次运行单独管理
+	if p.Type == AppTypeJob {
+		_, err := pm.triggerJob(p, "manual")
+		return err
+	}
+
+	// 上一次启动仍在执行 pre_start 钩子
+	if p.starting > 0 {
+		return fmt.Errorf("进程 '%s' 正在启动", p.Name)
+	}
+
+	cred, err := p.processCredential()
+	if err != nil {
+		return err
+	}
+
+	// 创建日志文件，属主与运行应用的用户一致，日志目录不存在时自动创建
+	os.MkdirAll(filepath.Dir(p.LogFile), 0755)
+	os.MkdirAll(filepath.Dir(p.ErrorFile), 0755)
 	logFile, err := os.OpenFile(p.LogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
 	if err != nil {
 		return fmt.Errorf("创建日志文件失败: %v", err)
 	}
 	p.logWriter = logFile
+	chownFile(p.LogFile, cred)
 
 	errorFile, err := os.OpenFile(p.ErrorFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
 	if err != nil {
@@ -143,37 +277,31 @@ func (pm *ProcessManager) startProcessInstance(p *Process) error {
 		return fmt.Errorf("创建错误日志文件失败: %v", err)
 	}
 	p.errorWriter = errorFile
+	chownFile(p.ErrorFile, cred)
 
-	// 创建命令
+	// 执行 pre_start 钩子并创建命令，失败则中止启动
+	// 钩子和 Go 应用的编译不持有锁，期间应用可能已被删除
 	ctx, cancel := context.WithCancel(context.Background())
-	p.cancelFunc = cancel
-
 	var cmd *exec.Cmd
-	if strings.HasSuffix(p.Script, ".js") || strings.HasSuffix(p.Script, ".ts") {
-		// Node.js 脚本
-		args := append([]string{p.Script}, p.Args...)
-		cmd = exec.CommandContext(ctx, "node", args...)
-	} else if strings.HasSuffix(p.Script, ".py") {
-		// Python 脚本
-		args := append([]string{p.Script}, p.Args...)
-		cmd = exec.CommandContext(ctx, "python", args...)
-	} else if strings.HasSuffix(p.Script, ".go") {
-		// Go 脚本
-		args := append([]string{"run", p.Script}, p.Args...)
-		cmd = exec.CommandContext(ctx, "go", args...)
-	} else {
-		// 其他可执行文件
-		cmd = exec.CommandContext(ctx, p.Script, p.Args...)
+	p.starting++
+	err = pm.runHook(p, "pre_start", p.PreStart, logFile, errorFile)
+	if err == nil && p.deleted {
+		err = fmt.Errorf("进程 '%s' 已被删除", p.Name)
 	}
-
-	// 设置工作目录
-	cmd.Dir = p.Cwd
-
-	// 设置环境变量
-	cmd.Env = os.Environ()
-	for key, value := range p.Env {
-		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", key, value))
+	if err == nil {
+		cmd, err = pm.buildCommand(ctx, p, errorFile)
 	}
+	p.starting--
+	if err != nil {
+		cancel()
+		p.logWriter.Close()
+		p.errorWriter.Close()
+		p.logWriter = nil
+		p.errorWriter = nil
+		p.Status = startFailedStatus(err)
+		return err
+	}
+	p.cancelFunc = cancel
 
 	// 设置标准输出和错误输出
 	cmd.Stdout = p.logWriter
@@ -188,26 +316,156 @@ func (pm *ProcessManager) startProcessInstance(p *Process) error {
 	}
 
 	p.cmd = cmd
+	p.exited = make(chan struct{})
 	p.PID = cmd.Process.Pid
 	p.Status = StatusOnline
 	p.StartTime = time.Now()
+	recordProcessIdentity(p)
+	resetHealth(p)
 
 	// 保存PID文件
 	pidFile := filepath.Join(pm.dataDir, "pids", fmt.Sprintf("%s.pid", p.Name))
 	os.WriteFile(pidFile, []byte(strconv.Itoa(p.PID)), 0644)
 
+	pm.recordEvent(p, "start", fmt.Sprintf("进程已启动 (PID: %d)", p.PID))
+
 	// 启动守护协程
 	go pm.watchProcess(p)
 
+	// 异步执行 post_start 钩子，runHook 在钩子执行期间释放锁，不影响查询和停止
+	// 日志文件在应用停止时关闭，钩子输出写入本次启动打开的文件
+	if p.PostStart != "" {
+		go func() {
+			p.mutex.Lock()
+			defer p.mutex.Unlock()
+			if p.logWriter == logFile {
+				pm.runHook(p, "post_start", p.PostStart, logFile, errorFile)
+			}
+		}()
+	}
+
 	return nil
 }
 
+// buildCommand 根据解释器配置创建命令，并设置工作目录和环境变量
+// Go 应用会先编译到缓存再直接执行，编译输出写入 stderr（调用方需持有 p.mutex，编译期间会释放锁）
+func (pm *ProcessManager) buildCommand(ctx context.Context, p *Process, stderr io.Writer) (*exec.Cmd, error) {
+	interpreter, interpreterArgs, err := pm.resolveInterpreter(p)
+	if err != nil {
+		return nil, err
+	}
+
+	// 环境变量，不含路径分隔符的命令按其中的 PATH 查找
+	env, err := pm.buildEnv(p)
+	if err != nil {
+		return nil, err
+	}
+
+	var cmd *exec.Cmd
+	if p.Command != "" {
+		// shell 命令模式
+		cmd = appShellCommand(ctx, p.Command, p.Name, p.Args)
+	} else if isGoRun(interpreter, interpreterArgs) {
+		binary, err := pm.buildGoBinary(ctx, p, interpreter, interpreterArgs[1:], stderr)
+		if err != nil {
+			return nil, err
+		}
+		cmd = exec.CommandContext(ctx, binary, p.Args...)
+	} else if interpreter != "" {
+		path, err := lookPathIn(interpreter, envPath(env), p.Cwd)
+		if err != nil {
+			return nil, err
+		}
+		args := append([]string{}, interpreterArgs...)
+		args = append(append(args, p.Script), p.Args...)
+		cmd = exec.CommandContext(ctx, path, args...)
+	} else {
+		// 直接执行可执行文件
+		path, err := lookPathIn(p.Script, envPath(env), p.Cwd)
+		if err != nil {
+			return nil, err
+		}
+		cmd = exec.CommandContext(ctx, path, p.Args...)
+	}
+
+	// 看门狗心跳
+	if p.WatchdogTimeout > 0 {
+		watchdogEnv, err := pm.openWatchdog(p)
+		if err != nil {
+			return nil, err
+		}
+		env = append(env, watchdogEnv...)
+	}
+
+	// 设置工作目录和环境变量
+	cmd.Dir = p.Cwd
+	cmd.Env = env
+
+	// 独立进程组，便于暂停和恢复整个进程树
+	setProcessGroup(cmd)
+
+	// 以配置的用户和组运行
+	cred, err := p.processCredential()
+	if err != nil {
+		return nil, err
+	}
+	if err := setCredential(cmd, cred); err != nil {
+		return nil, err
+	}
+
+	// 资源限制和 cgroup 需要在 exec 前设置
+	cgroupDir, err := pm.setupCgroup(p)
+	if err != nil {
+		return nil, err
+	}
+	if err := wrapWithLimits(cmd, p.Limits, cred, cgroupDir); err != nil {
+		return nil, err
+	}
+
+	return cmd, nil
+}
+
+// buildEnv 构建应用的环境变量，优先级从低到高为守护进程环境、env_file、env（含环境配置）
+func (pm *ProcessManager) buildEnv(p *Process) ([]string, error) {
+	fileEnv, err := loadEnvFiles(p)
+	if err != nil {
+		return nil, err
+	}
+
+	// 密钥引用只在这里解析，不会写入进程记录
+	appEnv, err := resolveSecretEnv(p.effectiveEnv())
+	if err != nil {
+		return nil, err
+	}
+
+	env := os.Environ()
+	for key, value := range fileEnv {
+		env = append(env, fmt.Sprintf("%s=%s", key, value))
+	}
+	for key, value := range appEnv {
+		env = append(env, fmt.Sprintf("%s=%s", key, value))
+	}
+	return env, nil
+}
+
+// recordEvent 记录进程事件，只保留最近的事件（调用方需持有 p.mutex）
+func (pm *ProcessManager) recordEvent(p *Process, eventType, message string) {
+	p.Events = append(p.Events, ProcessEvent{
+		Time:    time.Now(),
+		Type:    eventType,
+		Message: message,
+	})
+	if len(p.Events) > maxProcessEvents {
+		p.Events = p.Events[len(p.Events)-maxProcessEvents:]
+	}
+}
+
 // StopProcess 停止进程
 func (pm *ProcessManager) StopProcess(nameOrID string) error {
-	pm.mutex.Lock()
-	defer pm.mutex.Unlock()
-
+	// 停止钩子和等待进程退出期间不持有 pm.mutex
+	pm.mutex.RLock()
 	process := pm.findProcess(nameOrID)
+	pm.mutex.RUnlock()
 	if process == nil {
 		return fmt.Errorf("未找到进程: %s", nameOrID)
 	}
@@ -220,11 +478,30 @@ func (pm *ProcessManager) stopProcessInstance(p *Process) error {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
-	if p.Status != StatusOnline {
+	// 空闲的任务也可以停止，用于停用调度
+	idleJob := p.Type == AppTypeJob && (p.Status == StatusOneTime || p.Status == StatusErrored || p.Status == StatusBuildFailed)
+	if !p.isRunning() && !idleJob {
 		return fmt.Errorf("进程 '%s' 当前状态为 %s，无法停止", p.Name, p.Status)
 	}
 
+	// 暂停的进程无法响应 SIGTERM，先恢复运行
+	if p.Status == StatusPaused {
+		pm.resumeProcessGroups(p)
+	}
+
 	p.Status = StatusStopping
+	p.stops++
+
+	// 钩子输出写入应用日志，任务没有常驻的日志文件，为钩子单独打开
+	stdout, stderr := p.logWriter, p.errorWriter
+	if stdout == nil && (p.PreStop != "" || p.PostStop != "") {
+		stdout, stderr = openHookLogs(p)
+	}
+
+	// 执行 pre_stop 钩子，失败不影响停止
+	if stdout != nil {
+		pm.runHook(p, "pre_stop", p.PreStop, stdout, stderr)
+	}
 
 	// 停止文件监控
 	if p.Watch && p.watcherStop != nil {
@@ -239,49 +516,65 @@ func (pm *ProcessManager) stopProcessInstance(p *Process) error {
 		p.cancelFunc()
 	}
 
+	// 终止任务正在进行的运行
+	if p.Type == AppTypeJob {
+		pm.stopJobRuns(p)
+	}
+
 	// 尝试优雅关闭
 	if p.cmd != nil && p.cmd.Process != nil {
-		// 发送SIGTERM信号
-		err := p.cmd.Process.Signal(syscall.SIGTERM)
+		// 向进程组发送SIGTERM信号，shell 命令派生的子进程一并退出
+		// 进程由 watchProcess 回收，这里等待其关闭 exited
+		pid := p.cmd.Process.Pid
+		kill := func() {
+			if sendSignal(pid, syscall.SIGKILL) != nil {
+				p.cmd.Process.Kill()
+			}
+		}
+		err := sendSignal(pid, syscall.SIGTERM)
 		if err == nil {
 			// 等待5秒让进程优雅退出
-			done := make(chan error, 1)
-			go func() {
-				done <- p.cmd.Wait()
-			}()
-
 			select {
 			case <-time.After(5 * time.Second):
 				// 强制杀死进程
-				p.cmd.Process.Kill()
-				<-done
-			case <-done:
+				kill()
+				<-p.exited
+			case <-p.exited:
 				// 进程已优雅退出
 			}
 		} else {
 			// 直接杀死进程
-			p.cmd.Process.Kill()
-			p.cmd.Wait()
+			kill()
+			<-p.exited
 		}
+	} else if p.PID > 0 && len(p.activeRuns) == 0 {
+		// 守护进程重启后接管的进程，任务正在进行的运行已由 stopJobRuns 终止
+		pm.stopAdoptedProcess(p)
 	}
 
+	// 结束应用派生的残留进程
+	pm.cleanupCgroup(p)
+	pm.closeWatchdog(p)
+
 	p.Status = StatusStopped
 	p.PID = 0
-
-	// 关闭日志文件
-	if p.logWriter != nil {
-		p.logWriter.Close()
-		p.logWriter = nil
-	}
-	if p.errorWriter != nil {
-		p.errorWriter.Close()
-		p.errorWriter = nil
-	}
+	p.Health = ""
+	pm.recordEvent(p, "stop", "进程已停止")
 
 	// 删除PID文件
 	pidFile := filepath.Join(pm.dataDir, "pids", fmt.Sprintf("%s.pid", p.Name))
 	os.Remove(pidFile)
 
+	// 日志文件先与进程解绑，post_stop 执行期间应用可能被再次启动
+	p.logWriter, p.errorWriter = nil, nil
+
+	// 执行 post_stop 钩子
+	if stdout != nil {
+		pm.runHook(p, "post_stop", p.PostStop, stdout, stderr)
+		stdout.Close()
+		stderr.Close()
+	}
+
 	pm.saveProcesses()
 	return nil
 }
@@ -293,17 +586,43 @@ func (pm *ProcessManager) RestartProcess(nameOrID string) error {
 		return fmt.Errorf("未找到进程: %s", nameOrID)
 	}
 
-	if process.Status == StatusOnline {
+	return pm.restartProcessInstance(process)
+}
+
+// restartProcessInstance 重启单个进程实例
+func (pm *ProcessManager) restartProcessInstance(process *Process) error {
+	process.mutex.Lock()
+	stops := process.stops
+	process.mutex.Unlock()
+
+	if process.isRunning() {
 		err := pm.stopProcessInstance(process)
 		if err != nil {
 			return fmt.Errorf("停止进程失败: %v", err)
 		}
+		stops++
 	}
 
 	// 等待一小段时间确保进程完全停止
 	time.Sleep(500 * time.Millisecond)
 
-	err := pm.startProcessInstance(process)
+	// 重启不持有 pm.mutex，期间应用可能已被用户停止或删除（如健康检查触发的重启），此时不再启动
+	usage := probeProcessPort(process)
+	pm.mutex.RLock()
+	process.mutex.Lock()
+	current := pm.processes[process.ID] == process && !process.deleted && process.stops == stops
+	process.mutex.Unlock()
+	if !current {
+		pm.mutex.RUnlock()
+		return fmt.Errorf("进程 '%s' 已被停止或删除，取消重启", process.Name)
+	}
+	err := pm.checkPortConflict(process, usage)
+	pm.mutex.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	err = pm.startProcessInstance(process)
 	if err != nil {
 		return fmt.Errorf("重启进程失败: %v", err)
 	}
@@ -315,45 +634,107 @@ func (pm *ProcessManager) RestartProcess(nameOrID string) error {
 
 // DeleteProcess 删除进程
 func (pm *ProcessManager) DeleteProcess(nameOrID string) error {
-	pm.mutex.Lock()
-	defer pm.mutex.Unlock()
-
+	pm.mutex.RLock()
 	process := pm.findProcess(nameOrID)
+	pm.mutex.RUnlock()
+
 	if process == nil {
 		return fmt.Errorf("未找到进程: %s", nameOrID)
 	}
 
+	return pm.deleteProcessInstance(process)
+}
+
+// deleteProcessInstance 停止并删除单个进程实例
+func (pm *ProcessManager) deleteProcessInstance(process *Process) error {
 	// 如果进程在运行，先停止它
-	if process.Status == StatusOnline {
+	if process.isRunning() {
 		pm.stopProcessInstance(process)
 	}
 
+	// 正在执行 pre_start 钩子的启动在钩子结束后中止
+	process.mutex.Lock()
+	process.deleted = true
+	pm.closeWatchdog(process)
+	process.mutex.Unlock()
+
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+
 	// 从进程列表中删除
 	delete(pm.processes, process.ID)
 
 	// 删除相关文件
 	pidFile := filepath.Join(pm.dataDir, "pids", fmt.Sprintf("%s.pid", process.Name))
 	os.Remove(pidFile)
+	if process.Type == AppTypeJob {
+		if runDir, err := pm.appDataDir("jobs", process.Name); err == nil {
+			os.RemoveAll(runDir)
+		}
+	}
+	if buildDir, err := pm.appDataDir("build", process.Name); err == nil {
+		os.RemoveAll(buildDir)
+	}
 
 	pm.saveProcesses()
 	return nil
 }
 
+// appDataDir 返回数据目录下 kind 子目录中应用专属的目录，名称不能安全地用作目录名时返回错误
+// 旧版本保存的进程没有经过名称检查，删除目录前必须通过这里取路径
+func (pm *ProcessManager) appDataDir(kind, name string) (string, error) {
+	if err := validateAppName(name); err != nil {
+		return "", err
+	}
+	return filepath.Join(pm.dataDir, kind, name), nil
+}
+
 // GetProcessList 获取进程列表
 func (pm *ProcessManager) GetProcessList() []*Process {
+	// 监听端口的 socket 表和进程关系在加锁前读取，所有应用共用，短时间内的多次刷新复用同一份
+	ports := pm.listPortSnapshot()
+
 	pm.mutex.RLock()
 	defer pm.mutex.RUnlock()
 
 	processes := make([]*Process, 0, len(pm.processes))
 	for _, p := range pm.processes {
 		// 更新进程统计信息
-		pm.updateProcessStats(p)
+		pm.updateProcessStats(p, ports)
 		processes = append(processes, p)
 	}
 
 	return processes
 }
 
+// sortProcessesByDependency 按依赖关系排序进程，被依赖的进程排在前面
+func sortProcessesByDependency(processes []*Process) []*Process {
+	sort.Slice(processes, func(i, j int) bool {
+		return processes[i].ID < processes[j].ID
+	})
+
+	names := make([]string, 0, len(processes))
+	deps := make(map[string][]string)
+	byName := make(map[string][]*Process)
+	for _, p := range processes {
+		names = append(names, p.Name)
+		deps[p.Name] = append(deps[p.Name], p.DependsOn...)
+		byName[p.Name] = append(byName[p.Name], p)
+	}
+
+	// 存在循环依赖时保持按ID排序
+	order, err := dependencyOrder(names, deps)
+	if err != nil {
+		return processes
+	}
+
+	sorted := make([]*Process, 0, len(processes))
+	for _, name := range order {
+		sorted = append(sorted, byName[name]...)
+	}
+	return sorted
+}
+
 // findProcess 查找进程（通过名称或ID）
 func (pm *ProcessManager) findProcess(nameOrID string) *Process {
 	// 尝试按ID查找
@@ -383,7 +764,7 @@ func (pm *ProcessManager) watchProcess(p *Process) {
 	}
 
 	for {
-		if p.cmd == nil {
+		if p.cmd == nil && p.PID == 0 {
 			if p.logWriter != nil {
 				logMsg := fmt.Sprintf("[%s] watchProcess: cmd 为空，退出监控",
 					time.Now().Format("2006-01-02 15:04:05"))
@@ -399,7 +780,16 @@ func (pm *ProcessManager) watchProcess(p *Process) {
 			p.logWriter.WriteString(logMsg + "\n")
 		}
 
-		err := p.cmd.Wait()
+		// 接管的进程不是守护进程的子进程，只能轮询等待退出
+		var err error
+		if p.cmd != nil {
+			// 只在这里调用 Wait，停止进程时通过 exited 等待退出
+			exited := p.exited
+			err = p.cmd.Wait()
+			close(exited)
+		} else {
+			err = pm.waitAdoptedProcess(p)
+		}
 
 		// 添加调试日志
 		if p.logWriter != nil {
@@ -419,9 +809,28 @@ func (pm *ProcessManager) watchProcess(p *Process) {
 			break
 		}
 
-		// 进程意外退出
+		// 进程意外退出，cgroup 的 OOM 计数增加说明被 OOM killer 终止
 		p.Status = StatusErrored
 		p.PID = 0
+		if oomKills := readOOMKills(p.CgroupPath); oomKills > p.oomKills {
+			p.oomKills = oomKills
+			p.ExitReason = "oom"
+			pm.recordEvent(p, "oom", fmt.Sprintf("进程内存超出 cgroup 限制，被 OOM killer 终止: %v", err))
+		} else if !p.watchdogFired.IsZero() {
+			p.ExitReason = "watchdog"
+			pm.recordEvent(p, "watchdog", fmt.Sprintf("进程因心跳超时被终止: %v", err))
+		} else if !p.healthFired.IsZero() {
+			p.ExitReason = "unhealthy"
+			pm.recordEvent(p, "health", fmt.Sprintf("进程因健康检查失败被终止: %v", err))
+		} else {
+			p.ExitReason = "crash"
+			pm.recordEvent(p, "exit", fmt.Sprintf("进程意外退出: %v", err))
+		}
+		p.watchdogFired = time.Time{}
+		p.healthFired = time.Time{}
+
+		// 结束崩溃的应用遗留的子进程并删除 cgroup，重启时重新创建
+		pm.cleanupCgroup(p)
 
 		// 记录调试信息
 		if p.logWriter != nil {
@@ -463,7 +872,7 @@ func (pm *ProcessManager) watchProcess(p *Process) {
 			restartErr := pm.startProcessInstance(p)
 			if restartErr != nil {
 				p.mutex.Lock()
-				p.Status = StatusErrored
+				p.Status = startFailedStatus(restartErr)
 				if p.logWriter != nil {
 					logMsg := fmt.Sprintf("[%s] 重启失败: %v",
 						time.Now().Format("2006-01-02 15:04:05"), restartErr)
@@ -477,6 +886,8 @@ func (pm *ProcessManager) watchProcess(p *Process) {
 						time.Now().Format("2006-01-02 15:04:05"), p.PID)
 					p.logWriter.WriteString(logMsg + "\n")
 				}
+				// 新进程由 startProcessInstance 启动的监控协程接管
+				return
 			}
 		} else {
 			// 达到最大重启次数
@@ -487,6 +898,7 @@ func (pm *ProcessManager) watchProcess(p *Process) {
 				p.logWriter.WriteString(logMsg + "\n")
 			}
 			p.mutex.Unlock()
+			pm.saveProcesses()
 			break
 		}
 	}
@@ -499,18 +911,22 @@ func (pm *ProcessManager) watchProcess(p *Process) {
 	}
 }
 
-// updateProcessStats 更新进程统计信息
-func (pm *ProcessManager) updateProcessStats(p *Process) {
+// updateProcessStats 更新进程统计信息，监听端口从 ports 中查找
+func (pm *ProcessManager) updateProcessStats(p *Process, ports *portSnapshot) {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
-	if p.Status != StatusOnline || p.PID == 0 {
+	if !p.isRunning() || p.PID == 0 {
 		p.CPUUsage = 0
 		p.MemoryUsage = 0
 		p.Uptime = 0
+		p.Ports = nil
 		return
 	}
 
+	// 进程树中所有进程监听的端口
+	p.Ports = ports.listeningPorts(p.PID, p.CgroupPath)
+
 	// 使用gopsutil获取进程信息
 	proc, err := process.NewProcess(int32(p.PID))
 	if err != nil {
@@ -527,6 +943,22 @@ func (pm *ProcessManager) updateProcessStats(p *Process) {
 		p.MemoryUsage = memInfo.RSS
 	}
 
+	// 应用位于独立 cgroup 时使用 cgroup 的总量，包含其派生的所有子进程
+	if p.CgroupPath != "" {
+		if memory, cpuUsec, err := readCgroupStats(p.CgroupPath); err == nil {
+			p.MemoryUsage = memory
+			now := time.Now()
+			if !p.cgroupSampleTime.IsZero() && cpuUsec >= p.cgroupCPUUsec {
+				elapsed := now.Sub(p.cgroupSampleTime).Microseconds()
+				if elapsed > 0 {
+					p.CPUUsage = float64(cpuUsec-p.cgroupCPUUsec) / float64(elapsed) * 100
+				}
+			}
+			p.cgroupCPUUsec = cpuUsec
+			p.cgroupSampleTime = now
+		}
+	}
+
 	// 计算运行时间
 	if !p.StartTime.IsZero() {
 		p.Uptime = time.Since(p.StartTime)
@@ -540,8 +972,10 @@ func (pm *ProcessManager) saveProcesses() {
 		return
 	}
 
+	// 进程记录中包含环境变量，仅允许守护进程用户读取
 	processFile := filepath.Join(pm.dataDir, "processes.json")
-	os.WriteFile(processFile, data, 0644)
+	os.WriteFile(processFile, data, 0600)
+	os.Chmod(processFile, 0600)
 }
 
 // commandLoop 守护进程命令处理循环
@@ -549,6 +983,9 @@ func (pm *ProcessManager) commandLoop() {
 	commandDir := filepath.Join(pm.dataDir, "commands")
 	os.MkdirAll(commandDir, 0755)
 
+	// 每个命令在单独的协程中处理，耗时的钩子或编译不阻塞其他命令
+	var inFlight sync.Map
+
 	for {
 		// 检查命令文件
 		files, err := os.ReadDir(commandDir)
@@ -563,7 +1000,13 @@ func (pm *ProcessManager) commandLoop() {
 			}
 
 			cmdFile := filepath.Join(commandDir, file.Name())
-			pm.processCommand(cmdFile)
+			if _, busy := inFlight.LoadOrStore(cmdFile, true); busy {
+				continue
+			}
+			go func() {
+				defer inFlight.Delete(cmdFile)
+				pm.processCommand(cmdFile)
+			}()
 		}
 
 		time.Sleep(1 * time.Second)
@@ -581,13 +1024,12 @@ func (pm *ProcessManager) processCommand(cmdFile string) {
 	// 删除命令文件
 	defer os.Remove(cmdFile)
 
-	// 解析命令
-	parts := strings.Split(strings.TrimSpace(string(data)), "\n")
-	if len(parts) < 1 {
-		return
+	// 解析命令，只去掉首行的空白，其余行按位置解析，空行（如未指定的命名空间）同样有效
+	parts := strings.Split(strings.TrimRight(string(data), "\r"), "\n")
+	for i := range parts {
+		parts[i] = strings.TrimSuffix(parts[i], "\r")
 	}
-
-	command := parts[0]
+	command := strings.TrimSpace(parts[0])
 	responseFile := strings.Replace(cmdFile, ".cmd", ".resp", 1)
 
 	switch command {
@@ -611,39 +1053,39 @@ func (pm *ProcessManager) processCommand(cmdFile string) {
 			os.WriteFile(responseFile, []byte(response), 0644)
 		}
 
-	case "STOP":
-		if len(parts) >= 2 {
-			nameOrID := parts[1]
-			err := pm.StopProcess(nameOrID)
-			if err != nil {
-				os.WriteFile(responseFile, []byte("ERROR: "+err.Error()), 0644)
-				return
-			}
-			response := fmt.Sprintf("SUCCESS: 停止 '%s'", nameOrID)
-			os.WriteFile(responseFile, []byte(response), 0644)
+	case "STOP", "RESTART", "DELETE", "PAUSE", "RESUME", "SIGNAL":
+		// 第二行为目标，第三行为并行度，第四、五行为标签选择器和命名空间，之后为命令的附加参数
+		// 缺少任何固定行时拒绝执行，避免丢失选择器后作用于全部应用
+		if len(parts) < 5 {
+			os.WriteFile(responseFile, []byte("ERROR: 命令格式错误"), 0644)
+			return
 		}
+		parallel, _ := strconv.Atoi(parts[2])
+		response := pm.handleBulkCommand(command, parts[1], parts[3], parts[4], parallel, parts[5:]...)
+		os.WriteFile(responseFile, []byte(response), 0644)
 
-	case "RESTART":
+	case "SET":
 		if len(parts) >= 2 {
-			nameOrID := parts[1]
-			err := pm.RestartProcess(nameOrID)
+			message, err := pm.SetProcessConfig(parts[1], parts[2:])
 			if err != nil {
 				os.WriteFile(responseFile, []byte("ERROR: "+err.Error()), 0644)
 				return
 			}
-			response := fmt.Sprintf("SUCCESS: 重启 '%s'", nameOrID)
-			os.WriteFile(responseFile, []byte(response), 0644)
+			os.WriteFile(responseFile, []byte("SUCCESS: "+message), 0644)
 		}
 
-	case "DELETE":
+	case "RUN":
 		if len(parts) >= 2 {
 			nameOrID := parts[1]
-			err := pm.DeleteProcess(nameOrID)
+			process, queued, err := pm.RunJob(nameOrID)
 			if err != nil {
 				os.WriteFile(responseFile, []byte("ERROR: "+err.Error()), 0644)
 				return
 			}
-			response := fmt.Sprintf("SUCCESS: 删除 '%s'", nameOrID)
+			response := fmt.Sprintf("SUCCESS: 运行任务 '%s' (ID: %d)", process.Name, process.ID)
+			if queued {
+				response = fmt.Sprintf("SUCCESS: 任务 '%s' 正在运行，本次运行已加入队列", process.Name)
+			}
 			os.WriteFile(responseFile, []byte(response), 0644)
 		}
 
@@ -804,6 +1246,11 @@ func (pm *ProcessManager) followLogFile(logFile string) {
 
 // sendCommand 发送命令给守护进程
 func (pm *ProcessManager) sendCommand(command string, args ...string) (string, error) {
+	return pm.sendCommandWithTimeout(10*time.Second, command, args...)
+}
+
+// sendCommandWithTimeout 发送命令给守护进程并在指定时间内等待响应
+func (pm *ProcessManager) sendCommandWithTimeout(timeout time.Duration, command string, args ...string) (string, error) {
 	commandDir := filepath.Join(pm.dataDir, "commands")
 	os.MkdirAll(commandDir, 0755)
 
@@ -825,7 +1272,8 @@ func (pm *ProcessManager) sendCommand(command string, args ...string) (string, e
 	}
 
 	// 等待响应文件
-	for i := 0; i < 100; i++ { // 最多等待10秒
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
 		time.Sleep(100 * time.Millisecond)
 		if _, err := os.Stat(responseFile); err == nil {
 			// 读取响应
@@ -880,10 +1328,22 @@ func (pm *ProcessManager) checkStartSignals() {
 
 	// 启动进程
 	if process.Status == StatusStopped {
-		err := pm.startProcessInstance(process)
+		usage := probeProcessPort(process)
+		pm.mutex.RLock()
+		err := pm.checkPortConflict(process, usage)
+		pm.mutex.RUnlock()
 		if err != nil {
 			process.mutex.Lock()
 			process.Status = StatusErrored
+			pm.recordEvent(process, "port", err.Error())
+			process.mutex.Unlock()
+			return
+		}
+
+		err = pm.startProcessInstance(process)
+		if err != nil {
+			process.mutex.Lock()
+			process.Status = startFailedStatus(err)
 			process.mutex.Unlock()
 		}
 	}
@@ -906,45 +1366,36 @@ func (pm *ProcessManager) loadProcesses() {
 
 	// 恢复进程状态
 	for id, p := range processes {
-		// 检查进程是否仍在运行
+		// 检查进程是否仍在运行，并确认 PID 没有被其他进程复用
 		if p.PID > 0 {
-			if proc, err := process.NewProcess(int32(p.PID)); err == nil {
-				if exists, _ := proc.IsRunning(); exists {
+			if matchesProcessIdentity(p) {
+				// 暂停的进程仍处于 SIGSTOP 状态，保留暂停状态
+				if p.Status != StatusPaused {
 					p.Status = StatusOnline
-					// 重新启动守护协程
-					go pm.watchProcess(p)
-				} else {
-					p.Status = StatusStopped
-					p.PID = 0
 				}
+			} else if p.Type == AppTypeJob && p.isRunning() {
+				// 运行被中断的任务恢复为空闲状态，定时任务继续参与调度
+				p.Status = idleJobStatus(p)
+				p.PID = 0
 			} else {
 				p.Status = StatusStopped
 				p.PID = 0
 			}
-		} else {
+		} else if p.Type == AppTypeJob && p.isRunning() {
+			p.Status = idleJobStatus(p)
+		} else if p.Type != AppTypeJob || p.Status == StatusStopping {
+			// 任务保留上次运行的结果状态
 			p.Status = StatusStopped
 		}
 
+		// 下次运行时间由调度器重新计算，守护进程停止期间错过的运行不再补跑
+		p.NextRun = time.Time{}
+
 		p.watcherStop = make(chan bool, 1)
 		pm.processes[id] = p
 
 		if id >= pm.nextID {
 			pm.nextID = id + 1
 		}
-
-		// 如果进程应该在运行但当前已停止，尝试重启
-		// 这处理了系统重启后的自动恢复场景
-		if p.Status == StatusStopped && !p.StartTime.IsZero() {
-			// 延迟一小段时间后尝试重启
-			go func(process *Process) {
-				time.Sleep(2 * time.Second)
-				err := pm.startProcessInstance(process)
-				if err != nil {
-					process.mutex.Lock()
-					process.Status = StatusErrored
-					process.mutex.Unlock()
-				}
-			}(p)
-		}
 	}
 }
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSortProcessesByDependency(t *testing.T) {
+	tests := []struct {
+		name      string
+		processes []*Process
+		want      []int
+	}{
+		{"sorted by id without deps", []*Process{
+			{ID: 3, Name: "c"},
+			{ID: 1, Name: "a"},
+			{ID: 2, Name: "b"},
+		}, []int{1, 2, 3}},
+		{"dependency first", []*Process{
+			{ID: 1, Name: "api", DependsOn: []string{"db"}},
+			{ID: 2, Name: "db"},
+		}, []int{2, 1}},
+		{"cluster instances stay together", []*Process{
+			{ID: 1, Name: "api", DependsOn: []string{"db"}},
+			{ID: 2, Name: "api", DependsOn: []string{"db"}},
+			{ID: 3, Name: "db"},
+		}, []int{3, 1, 2}},
+		// 存在循环依赖时保持按ID排序
+		{"cycle falls back to id order", []*Process{
+			{ID: 2, Name: "b", DependsOn: []string{"a"}},
+			{ID: 1, Name: "a", DependsOn: []string{"b"}},
+		}, []int{1, 2}},
+	}
+
+	for _, tt := range tests {
+		var got []int
+		for _, p := range sortProcessesByDependency(tt.processes) {
+			got = append(got, p.ID)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: sortProcessesByDependency = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
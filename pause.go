@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// isRunning 判断进程是否仍在运行（暂停的进程也视为运行中）
+func (p *Process) isRunning() bool {
+	return p.Status == StatusOnline || p.Status == StatusPaused
+}
+
+// runningPIDs 返回进程当前运行中的 PID，任务返回所有正在进行的运行（调用方需持有 p.mutex）
+func (p *Process) runningPIDs() []int {
+	if p.Type == AppTypeJob {
+		pids := make([]int, 0, len(p.activeRuns))
+		for _, handle := range p.activeRuns {
+			if handle.cmd.Process != nil {
+				pids = append(pids, handle.cmd.Process.Pid)
+			}
+		}
+		return pids
+	}
+
+	if p.PID > 0 {
+		return []int{p.PID}
+	}
+	return nil
+}
+
+// pauseProcessInstance 向进程组发送 SIGSTOP 暂停进程
+func (pm *ProcessManager) pauseProcessInstance(p *Process) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.Status != StatusOnline {
+		return fmt.Errorf("进程 '%s' 当前状态为 %s，无法暂停", p.Name, p.Status)
+	}
+
+	pids := p.runningPIDs()
+	if len(pids) == 0 {
+		return fmt.Errorf("进程 '%s' 没有正在运行的实例", p.Name)
+	}
+
+	for _, pid := range pids {
+		if err := pauseProcessGroup(pid); err != nil {
+			return fmt.Errorf("暂停进程失败 (PID: %d): %v", pid, err)
+		}
+	}
+
+	p.Status = StatusPaused
+	pm.recordEvent(p, "pause", fmt.Sprintf("进程已暂停 (SIGSTOP, PID: %v)", pids))
+	pm.saveProcesses()
+	return nil
+}
+
+// resumeProcessInstance 向进程组发送 SIGCONT 恢复暂停的进程
+func (pm *ProcessManager) resumeProcessInstance(p *Process) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.Status != StatusPaused {
+		return fmt.Errorf("进程 '%s' 当前状态为 %s，无法恢复", p.Name, p.Status)
+	}
+
+	if err := pm.resumeProcessGroups(p); err != nil {
+		return err
+	}
+
+	p.Status = StatusOnline
+	// 暂停期间无法发送心跳，恢复后重新计时
+	p.LastWatchdogPing = time.Now()
+	pm.recordEvent(p, "resume", "进程已恢复 (SIGCONT)")
+	pm.saveProcesses()
+	return nil
+}
+
+// resumeProcessGroups 恢复进程的所有进程组（调用方需持有 p.mutex）
+func (pm *ProcessManager) resumeProcessGroups(p *Process) error {
+	for _, pid := range p.runningPIDs() {
+		if err := resumeProcessGroup(pid); err != nil {
+			return fmt.Errorf("恢复进程失败 (PID: %d): %v", pid, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 进程列表复用端口快照的时长，CLI 等待依赖或任务时每 500ms 轮询一次 LIST，不必每次遍历 /proc
+const portSnapshotTTL = 2 * time.Second
+
+// ListenPort 进程正在监听的端口
+type ListenPort struct {
+	Protocol string `json:"protocol"` // tcp 或 udp
+	Address  string `json:"address"`
+	Port     int    `json:"port"`
+	PID      int    `json:"pid"`
+}
+
+// String 返回端口的简要形式，如 tcp/8080
+func (lp ListenPort) String() string {
+	return fmt.Sprintf("%s/%d", lp.Protocol, lp.Port)
+}
+
+// formatPorts 返回用于列表显示的端口，相同端口号的 IPv4/IPv6 监听只显示一次
+func formatPorts(ports []ListenPort) string {
+	if len(ports) == 0 {
+		return "-"
+	}
+	seen := make(map[string]bool)
+	var parts []string
+	for _, lp := range ports {
+		if !seen[lp.String()] {
+			seen[lp.String()] = true
+			parts = append(parts, lp.String())
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// sortPorts 按端口号、协议和地址排序
+func sortPorts(ports []ListenPort) {
+	sort.Slice(ports, func(i, j int) bool {
+		if ports[i].Port != ports[j].Port {
+			return ports[i].Port < ports[j].Port
+		}
+		if ports[i].Protocol != ports[j].Protocol {
+			return ports[i].Protocol < ports[j].Protocol
+		}
+		return ports[i].Address < ports[j].Address
+	})
+}
+
+// listPortSnapshot 返回进程列表使用的端口快照，距上次读取不足 portSnapshotTTL 时直接复用
+func (pm *ProcessManager) listPortSnapshot() *portSnapshot {
+	pm.portsMutex.Lock()
+	defer pm.portsMutex.Unlock()
+	if pm.ports == nil || time.Since(pm.portsTime) >= portSnapshotTTL {
+		pm.ports = newPortSnapshot()
+		pm.portsTime = time.Now()
+	}
+	return pm.ports
+}
+
+// declaredPort 返回应用声明的端口，未设置 port 时使用环境变量 PORT，都没有时返回 0
+func (p *Process) declaredPort() int {
+	if p.Port > 0 {
+		return p.Port
+	}
+	if port, err := strconv.Atoi(p.effectiveEnv()["PORT"]); err == nil && port > 0 && port <= 65535 {
+		return port
+	}
+	return 0
+}
+
+// declaredPort 返回应用配置声明的端口，规则与 Process.declaredPort 相同
+func (c *AppConfig) declaredPort() int {
+	if c.Type == string(AppTypeJob) {
+		return 0
+	}
+	p := &Process{Port: c.Port, Env: c.Env, Envs: c.Envs, EnvProfile: c.EnvProfile}
+	return p.declaredPort()
+}
+
+// portUsage 某个端口的占用情况
+type portUsage struct {
+	port     int
+	pid      int  // 占用者，无权查看时为 0
+	held     bool // 端口是否被占用
+	snapshot *portSnapshot
+}
+
+// probePort 读取端口的占用情况，需要遍历 /proc，调用方不应持有 pm.mutex；port 为 0 时不读取
+func probePort(port int) portUsage {
+	usage := portUsage{port: port}
+	if port == 0 {
+		return usage
+	}
+	usage.pid, usage.held = findPortOwner(port)
+	if usage.held && usage.pid > 0 {
+		usage.snapshot = newPortSnapshot()
+	}
+	return usage
+}
+
+// probeProcessPort 读取应用声明的端口的占用情况，调用方不应持有 pm.mutex 和 p.mutex
+func probeProcessPort(p *Process) portUsage {
+	p.mutex.Lock()
+	port := p.declaredPort()
+	if p.Type == AppTypeJob {
+		port = 0
+	}
+	p.mutex.Unlock()
+	return probePort(port)
+}
+
+// checkPortConflict 根据加锁前读取的占用情况检查端口 usage.port 能否分配给应用，错误信息包含占用者
+// 调用方需持有 pm.mutex，不能持有任何应用的 mutex
+func (pm *ProcessManager) checkPortConflict(p *Process, usage portUsage) error {
+	port := usage.port
+	if port == 0 || p.Type == AppTypeJob {
+		return nil
+	}
+
+	// 其他运行中的应用声明了同一端口，即使尚未开始监听也视为冲突
+	for _, other := range pm.processes {
+		if other == p || other.Type == AppTypeJob {
+			continue
+		}
+		other.mutex.Lock()
+		conflict := other.isRunning() && other.declaredPort() == port
+		name := other.Name
+		other.mutex.Unlock()
+		if conflict {
+			return fmt.Errorf("端口 %d 已分配给应用 '%s'", port, name)
+		}
+	}
+
+	if !usage.held {
+		return nil
+	}
+	pid := usage.pid
+	if pid <= 0 {
+		return fmt.Errorf("端口 %d 已被其他进程占用", port)
+	}
+
+	// 占用者属于其他受管应用时给出应用名称，属于应用自身（如 set 修改为正在监听的端口）时不算冲突
+	for _, other := range pm.processes {
+		other.mutex.Lock()
+		owned := other.isRunning() && other.PID > 0 && usage.snapshot.processTreeContains(other.PID, other.CgroupPath, pid)
+		name := other.Name
+		other.mutex.Unlock()
+		if owned && other == p {
+			return nil
+		}
+		if owned {
+			return fmt.Errorf("端口 %d 已被应用 '%s' 占用 (PID: %d)", port, name, pid)
+		}
+	}
+	return fmt.Errorf("端口 %d 已被进程 %s 占用 (PID: %d)", port, processName(pid), pid)
+}
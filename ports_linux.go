@@ -0,0 +1,252 @@
+package main
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// /proc/net 中的 socket 表及对应协议
+var procNetTables = []struct {
+	file     string
+	protocol string
+}{
+	{"tcp", "tcp"},
+	{"tcp6", "tcp"},
+	{"udp", "udp"},
+	{"udp6", "udp"},
+}
+
+// /proc/net/tcp 中 LISTEN 状态的编码
+const tcpListenState = "0A"
+
+// /proc/net/udp 中未连接 socket 的状态编码，远端地址为全零时表示仅绑定了本地端口
+const udpUnconnectedState = "07"
+
+// portSnapshot 某一时刻的监听 socket 表和进程父子关系，创建后只读，可在多次刷新间共用
+// 读取需要遍历 /proc/net 和所有进程的 stat，刷新进程列表时只读取一次，所有应用共用
+type portSnapshot struct {
+	sockets  map[uint64]ListenPort
+	children map[int][]int
+}
+
+// newPortSnapshot 读取当前的监听 socket 表和进程父子关系
+func newPortSnapshot() *portSnapshot {
+	return &portSnapshot{
+		sockets:  readListeningSockets(),
+		children: readProcessChildren(),
+	}
+}
+
+// listeningPorts 返回进程树（含 cgroup 中的进程）正在监听的 TCP 和 UDP 端口
+func (s *portSnapshot) listeningPorts(pid int, cgroupPath string) []ListenPort {
+	if len(s.sockets) == 0 {
+		return nil
+	}
+
+	var ports []ListenPort
+	seen := make(map[uint64]bool)
+	for _, member := range s.processTree(pid, cgroupPath) {
+		for _, inode := range socketInodes(member) {
+			// 同一 socket 可能被多个进程继承，只记录第一个
+			if lp, exists := s.sockets[inode]; exists && !seen[inode] {
+				seen[inode] = true
+				lp.PID = member
+				ports = append(ports, lp)
+			}
+		}
+	}
+	sortPorts(ports)
+	return ports
+}
+
+// findPortOwner 查找监听指定端口的进程，端口被占用但无权查看占用者时 pid 为 0
+func findPortOwner(port int) (int, bool) {
+	inodes := make(map[uint64]bool)
+	for inode, lp := range readListeningSockets() {
+		if lp.Port == port {
+			inodes[inode] = true
+		}
+	}
+	if len(inodes) == 0 {
+		return 0, false
+	}
+
+	for _, pid := range allPIDs() {
+		for _, inode := range socketInodes(pid) {
+			if inodes[inode] {
+				return pid, true
+			}
+		}
+	}
+	return 0, true
+}
+
+// readListeningSockets 读取 /proc/net 中处于监听状态的 socket，返回 inode 到端口的映射
+func readListeningSockets() map[uint64]ListenPort {
+	sockets := make(map[uint64]ListenPort)
+	for _, table := range procNetTables {
+		file, err := os.Open(filepath.Join("/proc/net", table.file))
+		if err != nil {
+			continue
+		}
+
+		scanner := bufio.NewScanner(file)
+		scanner.Scan() // 跳过表头
+		for scanner.Scan() {
+			// sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode
+			fields := strings.Fields(scanner.Text())
+			if len(fields) < 10 {
+				continue
+			}
+			state := fields[3]
+			if table.protocol == "tcp" && state != tcpListenState {
+				continue
+			}
+			if table.protocol == "udp" && (state != udpUnconnectedState || strings.Trim(fields[2], "0:") != "") {
+				continue
+			}
+
+			address, port, err := parseProcNetAddress(fields[1])
+			if err != nil {
+				continue
+			}
+			inode, err := strconv.ParseUint(fields[9], 10, 64)
+			if err != nil || inode == 0 {
+				continue
+			}
+			sockets[inode] = ListenPort{Protocol: table.protocol, Address: address, Port: port}
+		}
+		file.Close()
+	}
+	return sockets
+}
+
+// parseProcNetAddress 解析 /proc/net 中的地址，如 0100007F:1F90 -> 127.0.0.1, 8080
+// 地址按 32 位字以主机字节序（小端）存放
+func parseProcNetAddress(s string) (string, int, error) {
+	hexIP, hexPort, ok := strings.Cut(s, ":")
+	if !ok {
+		return "", 0, fmt.Errorf("无效的地址: %s", s)
+	}
+	port, err := strconv.ParseUint(hexPort, 16, 16)
+	if err != nil {
+		return "", 0, err
+	}
+	ip, err := hex.DecodeString(hexIP)
+	if err != nil || (len(ip) != net.IPv4len && len(ip) != net.IPv6len) {
+		return "", 0, fmt.Errorf("无效的地址: %s", s)
+	}
+	for i := 0; i < len(ip); i += 4 {
+		ip[i], ip[i+1], ip[i+2], ip[i+3] = ip[i+3], ip[i+2], ip[i+1], ip[i]
+	}
+	return net.IP(ip).String(), int(port), nil
+}
+
+// socketInodes 返回进程打开的 socket 的 inode
+func socketInodes(pid int) []uint64 {
+	dir := fmt.Sprintf("/proc/%d/fd", pid)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var inodes []uint64
+	for _, entry := range entries {
+		link, err := os.Readlink(filepath.Join(dir, entry.Name()))
+		if err != nil || !strings.HasPrefix(link, "socket:[") {
+			continue
+		}
+		inode, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(link, "socket:["), "]"), 10, 64)
+		if err == nil {
+			inodes = append(inodes, inode)
+		}
+	}
+	return inodes
+}
+
+// allPIDs 返回 /proc 中的所有进程 ID
+func allPIDs() []int {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil
+	}
+	var pids []int
+	for _, entry := range entries {
+		if pid, err := strconv.Atoi(entry.Name()); err == nil {
+			pids = append(pids, pid)
+		}
+	}
+	return pids
+}
+
+// readProcessChildren 读取所有进程的父进程，返回父进程 ID 到子进程的映射
+func readProcessChildren() map[int][]int {
+	children := make(map[int][]int)
+	for _, candidate := range allPIDs() {
+		data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", candidate))
+		if err != nil {
+			continue
+		}
+		// 进程名可能包含空格和括号，从最后一个 ) 之后解析: state ppid ...
+		stat := string(data)
+		fields := strings.Fields(stat[strings.LastIndex(stat, ")")+1:])
+		if len(fields) < 2 {
+			continue
+		}
+		if ppid, err := strconv.Atoi(fields[1]); err == nil {
+			children[ppid] = append(children[ppid], candidate)
+		}
+	}
+	return children
+}
+
+// processTree 返回以 pid 为根的进程树，应用位于独立 cgroup 时还包含 cgroup 中脱离进程树的进程
+func (s *portSnapshot) processTree(pid int, cgroupPath string) []int {
+	seen := map[int]bool{pid: true}
+	tree := []int{pid}
+	for i := 0; i < len(tree); i++ {
+		for _, child := range s.children[tree[i]] {
+			if !seen[child] {
+				seen[child] = true
+				tree = append(tree, child)
+			}
+		}
+	}
+
+	if cgroupPath != "" {
+		if data, err := os.ReadFile(filepath.Join(cgroupPath, "cgroup.procs")); err == nil {
+			for _, line := range strings.Fields(string(data)) {
+				if member, err := strconv.Atoi(line); err == nil && !seen[member] {
+					seen[member] = true
+					tree = append(tree, member)
+				}
+			}
+		}
+	}
+	return tree
+}
+
+// processTreeContains 判断 target 是否属于以 pid 为根的进程树
+func (s *portSnapshot) processTreeContains(pid int, cgroupPath string, target int) bool {
+	for _, member := range s.processTree(pid, cgroupPath) {
+		if member == target {
+			return true
+		}
+	}
+	return false
+}
+
+// processName 返回进程名，读取失败时返回 "?"
+func processName(pid int) string {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/comm", pid))
+	if err != nil {
+		return "?"
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,42 @@
+package main
+
+import "testing"
+
+func TestParseProcNetAddress(t *testing.T) {
+	tests := []struct {
+		input       string
+		wantAddress string
+		wantPort    int
+		wantErr     bool
+	}{
+		{"0100007F:1F90", "127.0.0.1", 8080, false},
+		{"00000000:0050", "0.0.0.0", 80, false},
+		{"0101A8C0:0016", "192.168.1.1", 22, false},
+		{"00000000:0000", "0.0.0.0", 0, false},
+		{"00000000:FFFF", "0.0.0.0", 65535, false},
+		{"00000000000000000000000000000000:0016", "::", 22, false},
+		{"00000000000000000000000001000000:1F90", "::1", 8080, false},
+		{"0000000000000000FFFF00000100007F:0050", "127.0.0.1", 80, false},
+		{"B80D0120000000000000000001000000:01BB", "2001:db8::1", 443, false},
+
+		{"", "", 0, true},
+		{"0100007F", "", 0, true},
+		{"0100007F:", "", 0, true},
+		{"0100007F:XYZ", "", 0, true},
+		{"0100007F:10000", "", 0, true},
+		{"01007F:0050", "", 0, true},
+		{"ZZ00007F:0050", "", 0, true},
+		{"0100007F00:0050", "", 0, true},
+	}
+
+	for _, tt := range tests {
+		address, port, err := parseProcNetAddress(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseProcNetAddress(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && (address != tt.wantAddress || port != tt.wantPort) {
+			t.Errorf("parseProcNetAddress(%q) = %s, %d, want %s, %d", tt.input, address, port, tt.wantAddress, tt.wantPort)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+//go:build !linux
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// portSnapshot 非 Linux 系统不读取 socket 表和进程关系
+type portSnapshot struct{}
+
+// newPortSnapshot 返回空的快照
+func newPortSnapshot() *portSnapshot {
+	return &portSnapshot{}
+}
+
+// listeningPorts 非 Linux 系统没有 /proc/net，不发现监听端口
+func (s *portSnapshot) listeningPorts(pid int, cgroupPath string) []ListenPort {
+	return nil
+}
+
+// findPortOwner 非 Linux 系统通过尝试监听端口判断是否被占用，无法确定占用者
+func findPortOwner(port int) (int, bool) {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return 0, isAddrInUse(err)
+	}
+	listener.Close()
+	return 0, false
+}
+
+// Windows 的 WSAEADDRINUSE，与 syscall.EADDRINUSE 的取值不同
+const wsaeaddrinuse = syscall.Errno(10048)
+
+// isAddrInUse 判断监听失败是否因为端口已被占用
+func isAddrInUse(err error) bool {
+	var errno syscall.Errno
+	if !errors.As(err, &errno) {
+		return false
+	}
+	return errno == syscall.EADDRINUSE || errno == wsaeaddrinuse
+}
+
+// processTreeContains 非 Linux 系统不检查进程树
+func (s *portSnapshot) processTreeContains(pid int, cgroupPath string, target int) bool {
+	return pid == target
+}
+
+// processName 返回进程名，读取失败时返回 "?"
+func processName(pid int) string {
+	proc, err := process.NewProcess(int32(pid))
+	if err != nil {
+		return "?"
+	}
+	name, err := proc.Name()
+	if err != nil {
+		return "?"
+	}
+	return name
+}
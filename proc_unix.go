@@ -0,0 +1,223 @@
+//go:build !windows
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// setProcessGroup 让子进程运行在独立的进程组中
+func setProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+}
+
+// daemonSysProcAttr 守护进程脱离当前终端会话
+func daemonSysProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
+
+// signalProcessGroup 向进程所在的进程组发送信号
+func signalProcessGroup(pid int, sig syscall.Signal) error {
+	pgid, err := syscall.Getpgid(pid)
+	if err != nil || pgid != pid {
+		// 不是进程组组长（例如接管的旧进程），只向进程本身发送
+		return syscall.Kill(pid, sig)
+	}
+	return syscall.Kill(-pgid, sig)
+}
+
+// pauseProcessGroup 暂停进程组
+func pauseProcessGroup(pid int) error {
+	return signalProcessGroup(pid, syscall.SIGSTOP)
+}
+
+// resumeProcessGroup 恢复进程组
+func resumeProcessGroup(pid int) error {
+	return signalProcessGroup(pid, syscall.SIGCONT)
+}
+
+// 支持通过 signal 命令发送的信号
+var signalNames = map[string]syscall.Signal{
+	"SIGHUP":   syscall.SIGHUP,
+	"SIGINT":   syscall.SIGINT,
+	"SIGQUIT":  syscall.SIGQUIT,
+	"SIGABRT":  syscall.SIGABRT,
+	"SIGKILL":  syscall.SIGKILL,
+	"SIGUSR1":  syscall.SIGUSR1,
+	"SIGUSR2":  syscall.SIGUSR2,
+	"SIGTERM":  syscall.SIGTERM,
+	"SIGALRM":  syscall.SIGALRM,
+	"SIGCONT":  syscall.SIGCONT,
+	"SIGSTOP":  syscall.SIGSTOP,
+	"SIGTSTP":  syscall.SIGTSTP,
+	"SIGTTIN":  syscall.SIGTTIN,
+	"SIGTTOU":  syscall.SIGTTOU,
+	"SIGWINCH": syscall.SIGWINCH,
+}
+
+// sendSignal 向进程组发送任意信号
+func sendSignal(pid int, sig syscall.Signal) error {
+	return signalProcessGroup(pid, sig)
+}
+
+// setCredential 以指定的用户和组运行命令
+func setCredential(cmd *exec.Cmd, cred *credential) error {
+	if cred == nil {
+		return nil
+	}
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Credential = &syscall.Credential{
+		Uid:    cred.uid,
+		Gid:    cred.gid,
+		Groups: cred.groups,
+		// 非 root 用户无权设置附加组
+		NoSetGroups: os.Geteuid() != 0,
+	}
+	return nil
+}
+
+// chownFile 将文件属主改为运行应用的用户，仅 root 运行时有效
+func chownFile(path string, cred *credential) {
+	if cred == nil || os.Geteuid() != 0 {
+		return
+	}
+	os.Chown(path, int(cred.uid), int(cred.gid))
+}
+
+// 资源限制名称到 rlimit 资源的映射
+var rlimitResources = map[string]int{
+	"nofile":  unix.RLIMIT_NOFILE,
+	"nproc":   unix.RLIMIT_NPROC,
+	"core":    unix.RLIMIT_CORE,
+	"as":      unix.RLIMIT_AS,
+	"stack":   unix.RLIMIT_STACK,
+	"memlock": unix.RLIMIT_MEMLOCK,
+}
+
+// wrapWithLimits 改为通过守护进程自身启动命令，由其加入 cgroup、设置资源限制并切换用户后再 exec 应用
+func wrapWithLimits(cmd *exec.Cmd, limits *ResourceLimits, cred *credential, cgroupDir string) error {
+	entries, err := limits.entries()
+	if err != nil || (len(entries) == 0 && cgroupDir == "") {
+		return err
+	}
+
+	limitSpec := "-"
+	if len(entries) > 0 {
+		limitSpec = formatLimitSpec(entries)
+	}
+	cgroupSpec := "-"
+	if cgroupDir != "" {
+		cgroupSpec = cgroupDir
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("获取程序路径失败: %v", err)
+	}
+
+	// 提高硬限制需要 root 权限，因此由 shim 先设置资源限制再切换用户
+	credSpec := "-"
+	if cred != nil {
+		groups := make([]string, 0, len(cred.groups))
+		for _, gid := range cred.groups {
+			groups = append(groups, strconv.FormatUint(uint64(gid), 10))
+		}
+		credSpec = fmt.Sprintf("%d:%d:%s", cred.uid, cred.gid, strings.Join(groups, ","))
+		cmd.SysProcAttr.Credential = nil
+	}
+
+	cmd.Args = append([]string{executable, limitShimCommand, limitSpec, credSpec, cgroupSpec, cmd.Path}, cmd.Args...)
+	cmd.Path = executable
+	return nil
+}
+
+// runLimitShim 加入 cgroup、设置资源限制、切换用户后执行应用，参数为: 限制 用户 cgroup 路径 argv...
+func runLimitShim(args []string) {
+	fail := func(format string, a ...interface{}) {
+		fmt.Fprintf(os.Stderr, "gopm2: "+format+"\n", a...)
+		os.Exit(127)
+	}
+
+	if len(args) < 5 {
+		fail("资源限制参数不完整")
+	}
+
+	// 在 exec 前加入 cgroup，应用派生的子进程也都在其中
+	if args[2] != "-" {
+		procs := filepath.Join(args[2], "cgroup.procs")
+		if err := os.WriteFile(procs, []byte(strconv.Itoa(os.Getpid())), 0644); err != nil {
+			fail("加入 cgroup 失败: %v", err)
+		}
+	}
+
+	for _, item := range strings.Split(args[0], ",") {
+		if item == "-" {
+			break
+		}
+		var name string
+		var rlim unix.Rlimit
+		if _, err := fmt.Sscanf(strings.Replace(item, "=", " ", 1), "%s %d:%d", &name, &rlim.Cur, &rlim.Max); err != nil {
+			fail("无效的资源限制: %s", item)
+		}
+		resource, exists := rlimitResources[name]
+		if !exists {
+			fail("不支持的资源限制: %s", name)
+		}
+		if rlim.Cur == rlimitInfinity {
+			rlim.Cur = unix.RLIM_INFINITY
+		}
+		if rlim.Max == rlimitInfinity {
+			rlim.Max = unix.RLIM_INFINITY
+		}
+		if err := unix.Setrlimit(resource, &rlim); err != nil {
+			fail("设置资源限制 %s 失败: %v", name, err)
+		}
+	}
+
+	if args[1] != "-" {
+		var uid, gid int
+		var groups string
+		parts := strings.SplitN(args[1], ":", 3)
+		if len(parts) != 3 {
+			fail("无效的用户参数: %s", args[1])
+		}
+		uid, _ = strconv.Atoi(parts[0])
+		gid, _ = strconv.Atoi(parts[1])
+		groups = parts[2]
+
+		// 非 root 用户无权设置附加组
+		if os.Geteuid() == 0 {
+			var gids []int
+			for _, g := range strings.Split(groups, ",") {
+				if id, err := strconv.Atoi(g); err == nil {
+					gids = append(gids, id)
+				}
+			}
+			if err := syscall.Setgroups(gids); err != nil {
+				fail("设置附加组失败: %v", err)
+			}
+		}
+		if err := syscall.Setgid(gid); err != nil {
+			fail("切换用户组失败: %v", err)
+		}
+		if err := syscall.Setuid(uid); err != nil {
+			fail("切换用户失败: %v", err)
+		}
+	}
+
+	err := syscall.Exec(args[3], args[4:], os.Environ())
+	fail("执行 %s 失败: %v", args[3], err)
+}
@@ -0,0 +1,81 @@
+//go:build windows
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup 让子进程运行在独立的进程组中
+func setProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.CreationFlags |= syscall.CREATE_NEW_PROCESS_GROUP
+}
+
+// daemonSysProcAttr 在新的进程组中启动守护进程
+func daemonSysProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{
+		CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP,
+	}
+}
+
+// 支持通过 signal 命令发送的信号
+var signalNames = map[string]syscall.Signal{
+	"SIGKILL": syscall.SIGKILL,
+}
+
+// sendSignal 发送信号，Windows 上只能强制结束进程
+func sendSignal(pid int, sig syscall.Signal) error {
+	if sig != syscall.SIGKILL {
+		return fmt.Errorf("Windows 仅支持发送 SIGKILL")
+	}
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return proc.Kill()
+}
+
+// setCredential 以指定的用户和组运行命令，Windows 不支持
+func setCredential(cmd *exec.Cmd, cred *credential) error {
+	if cred != nil {
+		return fmt.Errorf("Windows 不支持以其他用户运行应用")
+	}
+	return nil
+}
+
+// chownFile 将文件属主改为运行应用的用户，Windows 上不做处理
+func chownFile(path string, cred *credential) {}
+
+// wrapWithLimits 设置资源限制，Windows 不支持
+func wrapWithLimits(cmd *exec.Cmd, limits *ResourceLimits, cred *credential, cgroupDir string) error {
+	entries, err := limits.entries()
+	if err != nil {
+		return err
+	}
+	if len(entries) > 0 {
+		return fmt.Errorf("Windows 不支持资源限制")
+	}
+	return nil
+}
+
+// runLimitShim Windows 上不会以 shim 方式启动
+func runLimitShim(args []string) {
+	fmt.Fprintln(os.Stderr, "gopm2: Windows 不支持资源限制")
+	os.Exit(127)
+}
+
+// pauseProcessGroup 暂停进程组
+func pauseProcessGroup(pid int) error {
+	return fmt.Errorf("Windows 不支持暂停进程")
+}
+
+// resumeProcessGroup 恢复进程组
+func resumeProcessGroup(pid int) error {
+	return fmt.Errorf("Windows 不支持恢复进程")
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CronSchedule 解析后的 cron 表达式
+type CronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	domStar, dowStar              bool
+	every                         time.Duration
+}
+
+// cronField cron 字段的取值范围
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = []cronField{
+	{"分钟", 0, 59},
+	{"小时", 0, 23},
+	{"日", 1, 31},
+	{"月", 1, 12},
+	{"星期", 0, 7},
+}
+
+// cronAliases 预定义的调度表达式
+var cronAliases = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseCron 解析标准 5 字段 cron 表达式，支持 @daily 等别名和 @every <duration>
+func ParseCron(expr string) (*CronSchedule, error) {
+	expr = strings.TrimSpace(expr)
+
+	if strings.HasPrefix(expr, "@every ") {
+		duration, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(expr, "@every ")))
+		if err != nil {
+			return nil, fmt.Errorf("无效的间隔: %v", err)
+		}
+		if duration < time.Second {
+			return nil, fmt.Errorf("间隔不能小于1秒")
+		}
+		return &CronSchedule{every: duration}, nil
+	}
+
+	if alias, ok := cronAliases[expr]; ok {
+		expr = alias
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("表达式 '%s' 应包含5个字段 (分 时 日 月 星期)", expr)
+	}
+
+	var bits [5]uint64
+	for i, field := range fields {
+		b, err := parseCronField(field, cronFields[i])
+		if err != nil {
+			return nil, err
+		}
+		bits[i] = b
+	}
+
+	// 星期字段中 7 与 0 都表示星期日
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1
+	}
+
+	// 与 cron 一致，以 * 开头的字段（包括 */2）视为不限制日或星期
+	schedule := &CronSchedule{
+		minute:  bits[0],
+		hour:    bits[1],
+		dom:     bits[2],
+		month:   bits[3],
+		dow:     bits[4],
+		domStar: strings.HasPrefix(fields[2], "*"),
+		dowStar: strings.HasPrefix(fields[4], "*"),
+	}
+
+	// 日期不存在的表达式（如 2 月 30 日）永远不会触发
+	if schedule.Next(time.Now()).IsZero() {
+		return nil, fmt.Errorf("表达式 '%s' 没有匹配的日期，永远不会触发", expr)
+	}
+	return schedule, nil
+}
+
+// parseCronField 解析单个字段，支持 *、列表、范围和步长
+func parseCronField(field string, spec cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if idx := strings.Index(part, "/"); idx >= 0 {
+			s, err := strconv.Atoi(part[idx+1:])
+			if err != nil || s <= 0 {
+				return 0, fmt.Errorf("%s字段步长无效: %s", spec.name, part)
+			}
+			rangePart, step = part[:idx], s
+		}
+
+		low, high := spec.min, spec.max
+		if rangePart != "*" {
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err error
+			low, err = strconv.Atoi(bounds[0])
+			if err != nil {
+				return 0, fmt.Errorf("%s字段无效: %s", spec.name, part)
+			}
+			high = low
+			if len(bounds) == 2 {
+				high, err = strconv.Atoi(bounds[1])
+				if err != nil {
+					return 0, fmt.Errorf("%s字段无效: %s", spec.name, part)
+				}
+			} else if step > 1 {
+				high = spec.max
+			}
+		}
+
+		if low < spec.min || high > spec.max || low > high {
+			return 0, fmt.Errorf("%s字段超出范围 %d-%d: %s", spec.name, spec.min, spec.max, part)
+		}
+
+		for v := low; v <= high; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// Next 返回 t 之后的下一次运行时间
+func (s *CronSchedule) Next(t time.Time) time.Time {
+	if s.every > 0 {
+		return t.Add(s.every)
+	}
+
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+
+	return time.Time{}
+}
+
+// dayMatches 日和星期都有限制时满足任意一个即可
+func (s *CronSchedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// schedulerLoop 定时任务调度循环
+func (pm *ProcessManager) schedulerLoop() {
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		pm.mutex.RLock()
+		jobs := make([]*Process, 0)
+		for _, p := range pm.processes {
+			if p.Type == AppTypeJob && p.Schedule != "" {
+				jobs = append(jobs, p)
+			}
+		}
+		pm.mutex.RUnlock()
+
+		// 到期检查在循环中进行，触发运行时会执行 pre_start 钩子，只为到期的任务启动协程
+		for _, p := range jobs {
+			if pm.scheduleDue(p, now) {
+				go pm.runScheduledJob(p)
+			}
+		}
+	}
+}
+
+// cronSchedule 返回解析后的调度表达式，Schedule 未变化时复用上次的结果（调用方需持有 p.mutex）
+func (p *Process) cronSchedule() (*CronSchedule, error) {
+	if p.schedule == nil || p.scheduleExpr != p.Schedule {
+		schedule, err := ParseCron(p.Schedule)
+		if err != nil {
+			return nil, err
+		}
+		p.schedule, p.scheduleExpr = schedule, p.Schedule
+	}
+	return p.schedule, nil
+}
+
+// scheduleDue 检查任务是否到达运行时间，到达时计算下一次运行时间并返回 true
+func (pm *ProcessManager) scheduleDue(p *Process, now time.Time) bool {
+	// 停止任务时会持有锁等待运行退出，此时跳过，下一秒再检查，避免阻塞其他任务的调度
+	if !p.mutex.TryLock() {
+		return false
+	}
+	defer p.mutex.Unlock()
+
+	// 停止的任务不参与调度
+	if p.Status == StatusStopped || p.Status == StatusStopping {
+		p.NextRun = time.Time{}
+		return false
+	}
+
+	schedule, err := p.cronSchedule()
+	if err != nil {
+		return false
+	}
+
+	if p.NextRun.IsZero() {
+		p.NextRun = schedule.Next(now)
+		return false
+	}
+
+	if now.Before(p.NextRun) {
+		return false
+	}
+
+	// 暂停期间跳过计划运行
+	p.NextRun = schedule.Next(now)
+	return p.Status != StatusPaused
+}
+
+// runScheduledJob 触发一次计划运行
+func (pm *ProcessManager) runScheduledJob(p *Process) {
+	p.mutex.Lock()
+
+	// 到期后任务可能已被停止
+	if p.Status == StatusStopped || p.Status == StatusPaused {
+		p.mutex.Unlock()
+		return
+	}
+
+	queued, err := pm.triggerJob(p, "schedule")
+	p.mutex.Unlock()
+
+	if err != nil {
+		pm.writeJobLog(p, fmt.Sprintf("跳过计划运行: %v", err))
+	} else if queued {
+		pm.writeJobLog(p, "上一次运行尚未结束，计划运行已加入队列")
+	}
+
+	pm.saveProcesses()
+}
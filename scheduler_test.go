@@ -0,0 +1,99 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr bool
+	}{
+		// 合法表达式
+		{"* * * * *", false},
+		{"*/15 9-17 * * 1-5", false},
+		{"0,30 * * * *", false},
+		{"5/20 * * * *", false},
+		{"  0 0 * * *  ", false},
+		{"@daily", false},
+		{"@hourly", false},
+		{"@weekly", false},
+		{"@every 10m", false},
+		{"@every 1s", false},
+
+		// 边界值
+		{"59 23 31 12 7", false},
+		{"0 0 1 1 0", false},
+		{"0 0 29 2 *", false},
+
+		// 非法表达式
+		{"", true},
+		{"* * * *", true},
+		{"* * * * * *", true},
+		{"60 * * * *", true},
+		{"* 24 * * *", true},
+		{"* * 0 * *", true},
+		{"* * 32 * *", true},
+		{"* * * 13 *", true},
+		{"* * * * 8", true},
+		{"5-1 * * * *", true},
+		{"*/0 * * * *", true},
+		{"a * * * *", true},
+		{"1-a * * * *", true},
+		{"@every 500ms", true},
+		{"@every x", true},
+		{"@sometimes", true},
+
+		// 日期不存在，永远不会触发
+		{"0 0 30 2 *", true},
+		{"0 0 31 4 *", true},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseCron(tt.expr)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseCron(%q) error = %v, wantErr %v", tt.expr, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCronScheduleNext(t *testing.T) {
+	// 2024-01-15 是星期一
+	now := time.Date(2024, 1, 15, 10, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2024, 1, 15, 10, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, 1, 15, 10, 15, 0, 0, time.UTC)},
+		{"5/20 * * * *", time.Date(2024, 1, 15, 10, 25, 0, 0, time.UTC)},
+		{"30 10 * * *", time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)},
+		{"0 9 * * 1-5", time.Date(2024, 1, 16, 9, 0, 0, 0, time.UTC)},
+		{"7 10 * * *", time.Date(2024, 1, 16, 10, 7, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, 1, 21, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2024, 1, 21, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 31 * *", time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)},
+		// 日和星期都有限制时满足任意一个即可
+		{"0 0 13 * 5", time.Date(2024, 1, 19, 0, 0, 0, 0, time.UTC)},
+		// 以 * 开头的字段不算限制，日和星期需同时满足
+		{"0 0 13 * */2", time.Date(2024, 2, 13, 0, 0, 0, 0, time.UTC)},
+		{"0 0 */2 * 5", time.Date(2024, 1, 19, 0, 0, 0, 0, time.UTC)},
+		{"@every 90s", time.Date(2024, 1, 15, 10, 9, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		schedule, err := ParseCron(tt.expr)
+		if err != nil {
+			t.Errorf("ParseCron(%q) error = %v", tt.expr, err)
+			continue
+		}
+		if got := schedule.Next(now); !got.Equal(tt.want) {
+			t.Errorf("ParseCron(%q).Next() = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
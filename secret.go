@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// 密钥引用前缀，如 secret:file:/run/secrets/db、secret:env:DB_PASSWORD
+// 引用只在启动应用时解析，保存、显示和导出的都是引用本身
+const secretPrefix = "secret:"
+
+// 密钥文件的简写前缀，file:/run/secrets/db 等同于 secret:file:/run/secrets/db
+// 只接受绝对路径，避免把 file:test.db 这类普通值（如 SQLite DSN）当成引用
+const secretFilePrefix = "file:"
+
+// 显示密钥时使用的掩码
+const secretMask = "******"
+
+// 变量名包含这些词时视为敏感变量，即使不是密钥引用，显示时也使用掩码
+var sensitiveEnvWords = []string{"PASSWORD", "PASSWD", "SECRET", "TOKEN", "CREDENTIAL", "PRIVATE_KEY", "API_KEY", "ACCESS_KEY"}
+
+// isSecretRef 判断环境变量的值是否是密钥引用
+func isSecretRef(value string) bool {
+	if strings.HasPrefix(value, secretPrefix) {
+		return true
+	}
+	return strings.HasPrefix(value, secretFilePrefix) && filepath.IsAbs(strings.TrimPrefix(value, secretFilePrefix))
+}
+
+// parseSecretRef 解析密钥引用，返回来源类型 (file/env) 和位置
+func parseSecretRef(value string) (string, string, error) {
+	if !strings.HasPrefix(value, secretPrefix) && strings.HasPrefix(value, secretFilePrefix) {
+		return "file", strings.TrimPrefix(value, secretFilePrefix), nil
+	}
+	kind, location, ok := strings.Cut(strings.TrimPrefix(value, secretPrefix), ":")
+	if !ok || location == "" || (kind != "file" && kind != "env") {
+		return "", "", fmt.Errorf("无效的密钥引用: %s (应为 secret:file:<路径> 或 secret:env:<变量名>)", value)
+	}
+	return kind, location, nil
+}
+
+// resolveSecret 读取密钥引用的实际值，文件内容末尾的换行会被去掉
+func resolveSecret(value string) (string, error) {
+	kind, location, err := parseSecretRef(value)
+	if err != nil {
+		return "", err
+	}
+
+	switch kind {
+	case "file":
+		data, err := os.ReadFile(location)
+		if err != nil {
+			return "", fmt.Errorf("读取密钥文件失败: %v", err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	default:
+		secret, exists := os.LookupEnv(location)
+		if !exists {
+			return "", fmt.Errorf("守护进程环境中未设置密钥变量: %s", location)
+		}
+		return secret, nil
+	}
+}
+
+// resolveSecretEnv 解析环境变量中的密钥引用，返回新的 map，错误信息包含变量名
+func resolveSecretEnv(env map[string]string) (map[string]string, error) {
+	resolved := make(map[string]string, len(env))
+	for key, value := range env {
+		if isSecretRef(value) {
+			secret, err := resolveSecret(value)
+			if err != nil {
+				return nil, fmt.Errorf("环境变量 %s: %v", key, err)
+			}
+			value = secret
+		}
+		resolved[key] = value
+	}
+	return resolved, nil
+}
+
+// validateSecretRefs 检查环境变量中的密钥引用格式
+func validateSecretRefs(env map[string]string) error {
+	for key, value := range env {
+		if !isSecretRef(value) {
+			continue
+		}
+		if _, _, err := parseSecretRef(value); err != nil {
+			return fmt.Errorf("环境变量 %s: %v", key, err)
+		}
+	}
+	return nil
+}
+
+// isSensitiveEnvKey 判断变量名是否像密码、令牌等敏感信息
+func isSensitiveEnvKey(key string) bool {
+	upper := strings.ToUpper(key)
+	for _, word := range sensitiveEnvWords {
+		if strings.Contains(upper, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// displayEnvValue 返回用于显示的环境变量值，密钥引用显示为掩码和来源，敏感变量的明文值只显示掩码
+func displayEnvValue(key, value string) string {
+	if isSecretRef(value) {
+		return fmt.Sprintf("%s (%s)", secretMask, value)
+	}
+	if value != "" && isSensitiveEnvKey(key) {
+		return secretMask
+	}
+	return value
+}
+
+// maskEnv 将敏感变量的明文值替换为掩码，密钥引用保持不变（引用本身不含实际值）
+func maskEnv(env map[string]string) {
+	for key, value := range env {
+		if value != "" && !isSecretRef(value) && isSensitiveEnvKey(key) {
+			env[key] = secretMask
+		}
+	}
+}
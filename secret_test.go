@@ -0,0 +1,124 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseSecretRef(t *testing.T) {
+	// 简写形式只接受绝对路径，Windows 上需要盘符
+	abs := filepath.Join(t.TempDir(), "db")
+
+	tests := []struct {
+		value        string
+		isRef        bool
+		wantKind     string
+		wantLocation string
+		wantErr      bool
+	}{
+		{"secret:file:/run/secrets/db", true, "file", "/run/secrets/db", false},
+		{"secret:env:DB_PASSWORD", true, "env", "DB_PASSWORD", false},
+		{"secret:file:relative/path", true, "file", "relative/path", false},
+		{"file:" + abs, true, "file", abs, false},
+
+		// 相对路径的 file: 是普通值，如 SQLite DSN
+		{"file:test.db", false, "", "", false},
+		{"file:test.db?cache=shared", false, "", "", false},
+		{"plain", false, "", "", false},
+		{"", false, "", "", false},
+
+		{"secret:", true, "", "", true},
+		{"secret:file:", true, "", "", true},
+		{"secret:env", true, "", "", true},
+		{"secret:vault:db", true, "", "", true},
+	}
+
+	for _, tt := range tests {
+		if got := isSecretRef(tt.value); got != tt.isRef {
+			t.Errorf("isSecretRef(%q) = %v, want %v", tt.value, got, tt.isRef)
+			continue
+		}
+		if !tt.isRef {
+			continue
+		}
+		kind, location, err := parseSecretRef(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseSecretRef(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && (kind != tt.wantKind || location != tt.wantLocation) {
+			t.Errorf("parseSecretRef(%q) = %q, %q, want %q, %q", tt.value, kind, location, tt.wantKind, tt.wantLocation)
+		}
+	}
+}
+
+func TestResolveSecretEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db")
+	if err := os.WriteFile(path, []byte("s3cret\r\n\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GOPM2_TEST_TOKEN", "tok")
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    map[string]string
+		wantErr bool
+	}{
+		{"plain values unchanged", map[string]string{"A": "1", "DSN": "file:test.db"}, map[string]string{"A": "1", "DSN": "file:test.db"}, false},
+		{"file ref", map[string]string{"DB": "secret:file:" + path}, map[string]string{"DB": "s3cret"}, false},
+		{"file shorthand", map[string]string{"DB": "file:" + path}, map[string]string{"DB": "s3cret"}, false},
+		{"env ref", map[string]string{"TOKEN": "secret:env:GOPM2_TEST_TOKEN"}, map[string]string{"TOKEN": "tok"}, false},
+
+		{"missing file", map[string]string{"DB": "secret:file:" + path + ".missing"}, nil, true},
+		{"missing env", map[string]string{"TOKEN": "secret:env:GOPM2_TEST_UNSET"}, nil, true},
+		{"invalid ref", map[string]string{"X": "secret:vault:x"}, nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := resolveSecretEnv(tt.env)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: resolveSecretEnv error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: resolveSecretEnv = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDisplayEnvValue(t *testing.T) {
+	abs := filepath.Join(t.TempDir(), "db")
+
+	tests := []struct {
+		key   string
+		value string
+		want  string
+	}{
+		{"DB_PASSWORD", "secret:file:/run/secrets/db", "****** (secret:file:/run/secrets/db)"},
+		{"DB", "file:" + abs, "****** (file:" + abs + ")"},
+		{"DB_PASSWORD", "hunter2", "******"},
+		{"github_token", "abc", "******"},
+		{"STRIPE_API_KEY", "sk_live", "******"},
+		{"JWT_SECRET", "x", "******"},
+		{"DB_PASSWORD", "", ""},
+		{"PORT", "8080", "8080"},
+		{"KEYBOARD", "us", "us"},
+		{"DSN", "file:test.db", "file:test.db"},
+	}
+
+	for _, tt := range tests {
+		if got := displayEnvValue(tt.key, tt.value); got != tt.want {
+			t.Errorf("displayEnvValue(%q, %q) = %q, want %q", tt.key, tt.value, got, tt.want)
+		}
+	}
+
+	env := map[string]string{"DB_PASSWORD": "hunter2", "API_TOKEN": "secret:env:API_TOKEN", "PORT": "8080"}
+	maskEnv(env)
+	want := map[string]string{"DB_PASSWORD": "******", "API_TOKEN": "secret:env:API_TOKEN", "PORT": "8080"}
+	if !reflect.DeepEqual(env, want) {
+		t.Errorf("maskEnv = %v, want %v", env, want)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// 未指定命名空间时使用的默认值
+const defaultNamespace = "default"
+
+// labelRequirement 标签选择条件
+type labelRequirement struct {
+	key      string
+	value    string
+	operator string // "=", "!=" 或 "exists"
+}
+
+// parseLabelSelector 解析标签选择器，如 "team=payments,tier!=web,canary"
+func parseLabelSelector(selector string) ([]labelRequirement, error) {
+	var reqs []labelRequirement
+	for _, part := range strings.Split(selector, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		req := labelRequirement{operator: "exists", key: part}
+		if idx := strings.Index(part, "!="); idx >= 0 {
+			req = labelRequirement{operator: "!=", key: part[:idx], value: part[idx+2:]}
+		} else if idx := strings.Index(part, "="); idx >= 0 {
+			req = labelRequirement{operator: "=", key: part[:idx], value: part[idx+1:]}
+		}
+
+		req.key = strings.TrimSpace(req.key)
+		req.value = strings.TrimSpace(req.value)
+		if req.key == "" {
+			return nil, fmt.Errorf("无效的标签选择器: %s", part)
+		}
+		reqs = append(reqs, req)
+	}
+	return reqs, nil
+}
+
+// matchesSelector 判断进程是否满足命名空间和标签条件
+func (p *Process) matchesSelector(namespace string, reqs []labelRequirement) bool {
+	if namespace != "" && p.namespace() != namespace {
+		return false
+	}
+
+	for _, req := range reqs {
+		value, exists := p.Labels[req.key]
+		switch req.operator {
+		case "=":
+			if !exists || value != req.value {
+				return false
+			}
+		case "!=":
+			if exists && value == req.value {
+				return false
+			}
+		default:
+			if !exists {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// namespace 返回进程所在命名空间，兼容旧数据中的空值
+func (p *Process) namespace() string {
+	if p.Namespace == "" {
+		return defaultNamespace
+	}
+	return p.Namespace
+}
+
+// formatLabels 按键排序格式化标签
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return "-"
+	}
+
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key+"="+labels[key])
+	}
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLabelSelector(t *testing.T) {
+	tests := []struct {
+		selector string
+		want     []labelRequirement
+		wantErr  bool
+	}{
+		{"", nil, false},
+		{" , ", nil, false},
+		{"team=payments", []labelRequirement{{key: "team", value: "payments", operator: "="}}, false},
+		{"tier!=web", []labelRequirement{{key: "tier", value: "web", operator: "!="}}, false},
+		{"canary", []labelRequirement{{key: "canary", operator: "exists"}}, false},
+		{"team = payments , tier != web,canary", []labelRequirement{
+			{key: "team", value: "payments", operator: "="},
+			{key: "tier", value: "web", operator: "!="},
+			{key: "canary", operator: "exists"},
+		}, false},
+		{"team=", []labelRequirement{{key: "team", operator: "="}}, false},
+		{"team=a=b", []labelRequirement{{key: "team", value: "a=b", operator: "="}}, false},
+
+		{"=payments", nil, true},
+		{"!=web", nil, true},
+		{"team=a,=b", nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseLabelSelector(tt.selector)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseLabelSelector(%q) error = %v, wantErr %v", tt.selector, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseLabelSelector(%q) = %+v, want %+v", tt.selector, got, tt.want)
+		}
+	}
+}
+
+func TestMatchesSelector(t *testing.T) {
+	p := &Process{
+		Namespace: "prod",
+		Labels:    map[string]string{"team": "payments", "tier": "api"},
+	}
+	legacy := &Process{Labels: map[string]string{"team": "search"}}
+
+	tests := []struct {
+		process   *Process
+		namespace string
+		selector  string
+		want      bool
+	}{
+		{p, "", "", true},
+		{p, "prod", "", true},
+		{p, "staging", "", false},
+		{p, "", "team=payments", true},
+		{p, "", "team=search", false},
+		{p, "", "tier!=web", true},
+		{p, "", "tier!=api", false},
+		{p, "", "missing!=x", true},
+		{p, "", "team", true},
+		{p, "", "canary", false},
+		{p, "prod", "team=payments,tier=api", true},
+		{p, "prod", "team=payments,tier=web", false},
+
+		// 旧数据中没有命名空间的进程属于 default
+		{legacy, "default", "", true},
+		{legacy, "prod", "", false},
+		{legacy, "", "team=search", true},
+	}
+
+	for _, tt := range tests {
+		reqs, err := parseLabelSelector(tt.selector)
+		if err != nil {
+			t.Fatalf("parseLabelSelector(%q) error = %v", tt.selector, err)
+		}
+		if got := tt.process.matchesSelector(tt.namespace, reqs); got != tt.want {
+			t.Errorf("matchesSelector(%q, %q) on %v = %v, want %v", tt.namespace, tt.selector, tt.process.Labels, got, tt.want)
+		}
+	}
+}
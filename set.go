@@ -0,0 +1,363 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// settableField 可通过 set 修改的字段，parse 校验取值并返回实际修改进程的函数
+// restart 为 true 的字段只在应用下次启动时生效
+type settableField struct {
+	restart bool
+	parse   func(p *Process, value string) (func(p *Process), error)
+}
+
+// settableFields 可通过 set 修改的字段，env.<KEY> 和 labels.<KEY> 单独处理
+var settableFields = map[string]settableField{
+	"script": {true, func(p *Process, value string) (func(p *Process), error) {
+		if value == "" {
+			return nil, fmt.Errorf("不能为空")
+		}
+		// script 和 command 只能指定其一
+		return func(p *Process) { p.Script, p.Command = value, "" }, nil
+	}},
+	"command": {true, func(p *Process, value string) (func(p *Process), error) {
+		if value == "" {
+			return nil, fmt.Errorf("不能为空")
+		}
+		return func(p *Process) { p.Command, p.Script = value, "" }, nil
+	}},
+	"args":             listField(func(p *Process) *[]string { return &p.Args }),
+	"cwd":              stringField(true, func(p *Process) *string { return &p.Cwd }),
+	"env_file":         listField(func(p *Process) *[]string { return &p.EnvFile }),
+	"interpreter":      stringField(true, func(p *Process) *string { return &p.Interpreter }),
+	"interpreter_args": listField(func(p *Process) *[]string { return &p.InterpreterArgs }),
+	"log_file":         stringField(true, func(p *Process) *string { return &p.LogFile }),
+	"error_file":       stringField(true, func(p *Process) *string { return &p.ErrorFile }),
+	"env_profile": {true, func(p *Process, value string) (func(p *Process), error) {
+		if value != "" && !hasEnvProfile(p.Envs, value) {
+			return nil, fmt.Errorf("未定义环境配置: %s", value)
+		}
+		return func(p *Process) { p.EnvProfile = value }, nil
+	}},
+	"user": {true, func(p *Process, value string) (func(p *Process), error) {
+		if _, err := resolveCredential(value, p.Group, p.Groups); err != nil {
+			return nil, err
+		}
+		return func(p *Process) { p.User = value }, nil
+	}},
+	"group": {true, func(p *Process, value string) (func(p *Process), error) {
+		if _, err := resolveCredential(p.User, value, p.Groups); err != nil {
+			return nil, err
+		}
+		return func(p *Process) { p.Group = value }, nil
+	}},
+	"port": {true, func(p *Process, value string) (func(p *Process), error) {
+		port, err := strconv.Atoi(value)
+		if err != nil || port < 0 || port > 65535 {
+			return nil, fmt.Errorf("无效的端口: %s", value)
+		}
+		return func(p *Process) { p.Port = port }, nil
+	}},
+
+	// 以下字段由守护进程在运行时读取，修改后立即生效
+	"max_restarts": {false, func(p *Process, value string) (func(p *Process), error) {
+		maxRestarts, err := strconv.Atoi(value)
+		if err != nil || maxRestarts < 0 {
+			return nil, fmt.Errorf("需为非负整数: %s", value)
+		}
+		return func(p *Process) { p.MaxRestarts = maxRestarts }, nil
+	}},
+	"min_uptime":   durationField(func(p *Process) *time.Duration { return &p.MinUptime }),
+	"hook_timeout": durationField(func(p *Process) *time.Duration { return &p.HookTimeout }),
+	"pre_start":    stringField(false, func(p *Process) *string { return &p.PreStart }),
+	"post_start":   stringField(false, func(p *Process) *string { return &p.PostStart }),
+	"pre_stop":     stringField(false, func(p *Process) *string { return &p.PreStop }),
+	"post_stop":    stringField(false, func(p *Process) *string { return &p.PostStop }),
+	"watchdog_signal": {false, func(p *Process, value string) (func(p *Process), error) {
+		if value != "" {
+			if _, _, err := parseSignal(value); err != nil {
+				return nil, err
+			}
+		}
+		return func(p *Process) { p.WatchdogSignal = value }, nil
+	}},
+	"namespace": {false, func(p *Process, value string) (func(p *Process), error) {
+		if value == "" {
+			value = defaultNamespace
+		}
+		return func(p *Process) { p.Namespace = value }, nil
+	}},
+}
+
+// stringField 返回字符串字段，钩子等在使用时读取的字段 restart 为 false
+func stringField(restart bool, field func(p *Process) *string) settableField {
+	return settableField{restart, func(p *Process, value string) (func(p *Process), error) {
+		return func(p *Process) { *field(p) = value }, nil
+	}}
+}
+
+// listField 返回重启后生效的列表字段，取值为 JSON 数组或以空白分隔的列表
+func listField(field func(p *Process) *[]string) settableField {
+	return settableField{true, func(p *Process, value string) (func(p *Process), error) {
+		list, err := parseListValue(value)
+		if err != nil {
+			return nil, err
+		}
+		return func(p *Process) { *field(p) = list }, nil
+	}}
+}
+
+// durationField 返回立即生效的时长字段
+func durationField(field func(p *Process) *time.Duration) settableField {
+	return settableField{false, func(p *Process, value string) (func(p *Process), error) {
+		duration, err := time.ParseDuration(value)
+		if err != nil || duration < 0 {
+			return nil, fmt.Errorf("无效的时长: %s", value)
+		}
+		return func(p *Process) { *field(p) = duration }, nil
+	}}
+}
+
+// parseListValue 解析列表取值，如 '["--port", "8080"]' 或 "--port 8080"
+func parseListValue(value string) ([]string, error) {
+	if strings.HasPrefix(strings.TrimSpace(value), "[") {
+		var list []string
+		if err := json.Unmarshal([]byte(value), &list); err != nil {
+			return nil, fmt.Errorf("无效的 JSON 数组: %v", err)
+		}
+		return list, nil
+	}
+	return strings.Fields(value), nil
+}
+
+// setAssignment 一项已校验的修改，note 为应用修改时补充的说明
+type setAssignment struct {
+	key     string
+	display string
+	restart bool
+	apply   func(p *Process)
+	note    string
+}
+
+// parseSetAssignment 解析 key=value 形式的修改，不含 = 时表示删除 env.<KEY> 或 labels.<KEY>（调用方需持有 p.mutex）
+func parseSetAssignment(p *Process, assignment string) (*setAssignment, error) {
+	key, value, hasValue := strings.Cut(assignment, "=")
+	key = strings.TrimSpace(key)
+
+	if strings.HasPrefix(key, "env.") {
+		name := strings.TrimPrefix(key, "env.")
+		if !isVarName(name) {
+			return nil, fmt.Errorf("无效的环境变量名: %s", name)
+		}
+		if !hasValue {
+			a := &setAssignment{key: key, display: "(删除)", restart: true}
+			a.apply = func(p *Process) {
+				delete(p.Env, name)
+				if _, shadowed := p.Envs[p.EnvProfile][name]; shadowed {
+					a.note = fmt.Sprintf("环境配置 %s 中仍定义该变量", p.EnvProfile)
+				}
+			}
+			return a, nil
+		}
+		if isSecretRef(value) {
+			if _, _, err := parseSecretRef(value); err != nil {
+				return nil, err
+			}
+		}
+		a := &setAssignment{key: key, display: displayEnvValue(name, value), restart: true}
+		a.apply = func(p *Process) {
+			if profile := setEnvValue(p, name, value); profile != "" {
+				a.note = fmt.Sprintf("写入环境配置 %s", profile)
+			}
+		}
+		return a, nil
+	}
+
+	if strings.HasPrefix(key, "labels.") {
+		name := strings.TrimPrefix(key, "labels.")
+		if name == "" || strings.ContainsAny(name, "=!,") {
+			return nil, fmt.Errorf("无效的标签名: '%s'", name)
+		}
+		if !hasValue {
+			return &setAssignment{key: key, display: "(删除)", apply: func(p *Process) { delete(p.Labels, name) }}, nil
+		}
+		return &setAssignment{key: key, display: value, apply: func(p *Process) {
+			if p.Labels == nil {
+				p.Labels = make(map[string]string)
+			}
+			p.Labels[name] = value
+		}}, nil
+	}
+
+	field, exists := settableFields[key]
+	if !exists {
+		return nil, fmt.Errorf("不支持修改字段: %s (可修改: %s, env.<KEY>, labels.<KEY>)", key, strings.Join(settableFieldNames(), ", "))
+	}
+	if !hasValue {
+		return nil, fmt.Errorf("%s 缺少取值，应为 %s=<值>", key, key)
+	}
+	apply, err := field.parse(p, value)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", key, err)
+	}
+	return &setAssignment{key: key, display: value, restart: field.restart, apply: apply}, nil
+}
+
+// setEnvValue 设置应用的环境变量，当前环境配置定义了同名变量时写入环境配置，否则修改会被其覆盖
+// 返回写入的环境配置名称，写入基础 env 时为空（调用方需持有 p.mutex）
+func setEnvValue(p *Process, key, value string) string {
+	if profile := p.Envs[p.EnvProfile]; profile != nil {
+		if _, shadowed := profile[key]; shadowed {
+			profile[key] = value
+			return p.EnvProfile
+		}
+	}
+	if p.Env == nil {
+		p.Env = make(map[string]string)
+	}
+	p.Env[key] = value
+	return ""
+}
+
+// assignedPort 返回修改中 port 的新值，有多个时以最后一个为准，取值无效时由 parseSetAssignment 报错
+func assignedPort(assignments []string) (int, bool) {
+	port, found := 0, false
+	for _, assignment := range assignments {
+		key, value, hasValue := strings.Cut(assignment, "=")
+		if strings.TrimSpace(key) != "port" || !hasValue {
+			continue
+		}
+		if n, err := strconv.Atoi(value); err == nil {
+			port, found = n, true
+		}
+	}
+	return port, found
+}
+
+// settableFieldNames 返回可修改的字段名，按名称排序
+func settableFieldNames() []string {
+	names := make([]string, 0, len(settableFields))
+	for name := range settableFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetProcessConfig 修改已保存的应用配置，全部修改校验通过后才会应用
+// 返回每项修改的说明，只在重启后生效的字段会注明
+func (pm *ProcessManager) SetProcessConfig(nameOrID string, assignments []string) (string, error) {
+	pm.mutex.RLock()
+	p := pm.findProcess(nameOrID)
+	pm.mutex.RUnlock()
+	if p == nil {
+		return "", fmt.Errorf("未找到进程: %s", nameOrID)
+	}
+	if len(assignments) == 0 {
+		return "", fmt.Errorf("没有指定要修改的字段")
+	}
+
+	// 新端口的占用检查会锁定其他应用，需在锁定 p 之前进行
+	if port, ok := assignedPort(assignments); ok {
+		usage := probePort(port)
+		pm.mutex.RLock()
+		err := pm.checkPortConflict(p, usage)
+		pm.mutex.RUnlock()
+		if err != nil {
+			return "", fmt.Errorf("port: %v", err)
+		}
+	}
+
+	p.mutex.Lock()
+	parsed := make([]*setAssignment, 0, len(assignments))
+	for _, assignment := range assignments {
+		a, err := parseSetAssignment(p, assignment)
+		if err != nil {
+			p.mutex.Unlock()
+			return "", err
+		}
+		parsed = append(parsed, a)
+	}
+
+	// 应用未运行时所有修改都在下次启动时生效
+	pending := "重启后生效"
+	if !p.isRunning() {
+		pending = "下次启动时生效"
+	}
+
+	var lines, changes []string
+	needRestart := false
+	for _, a := range parsed {
+		a.apply(p)
+		changes = append(changes, fmt.Sprintf("%s=%s", a.key, a.display))
+		line := fmt.Sprintf("  %s = %s", a.key, a.display)
+		if a.note != "" {
+			line += fmt.Sprintf(" (%s)", a.note)
+		}
+		if a.restart {
+			line += fmt.Sprintf(" (%s)", pending)
+			needRestart = true
+		}
+		lines = append(lines, line)
+	}
+	pm.recordEvent(p, "config", "修改配置: "+strings.Join(changes, ", "))
+	name, running := p.Name, p.isRunning()
+	p.mutex.Unlock()
+
+	pm.saveProcesses()
+
+	message := fmt.Sprintf("更新 '%s'\n%s", name, strings.Join(lines, "\n"))
+	if needRestart && running {
+		message += fmt.Sprintf("\n使用 gopm2 restart %s 使修改生效", name)
+	}
+	return message, nil
+}
+
+// updateProcessEnv 处理 restart --update-env：切换环境配置、设置 -e 指定的变量（当前环境配置中定义的变量写入环境配置），
+// 并用调用方的环境刷新启动时从调用方复制的变量（如 PATH）
+func (pm *ProcessManager) updateProcessEnv(p *Process, profile string, env, callerEnv map[string]string) error {
+	if err := validateSecretRefs(env); err != nil {
+		return err
+	}
+	if profile != "" {
+		if err := pm.switchEnvProfile(p, profile); err != nil {
+			return err
+		}
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var changed []string
+	for key, value := range callerEnv {
+		if _, exists := env[key]; exists {
+			continue
+		}
+		if old, exists := p.Env[key]; exists && old != value {
+			p.Env[key] = value
+			changed = append(changed, key)
+		}
+	}
+	// 当前环境配置中定义的变量写入环境配置，否则 -e 指定的值会被覆盖
+	current := p.effectiveEnv()
+	for key, value := range env {
+		if old, exists := current[key]; exists && old == value {
+			continue
+		}
+		if profile := setEnvValue(p, key, value); profile != "" {
+			key += " (环境配置 " + profile + ")"
+		}
+		changed = append(changed, key)
+	}
+
+	if len(changed) > 0 {
+		sort.Strings(changed)
+		pm.recordEvent(p, "env", "更新环境变量: "+strings.Join(changed, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseSetAssignment(t *testing.T) {
+	newProcess := func() *Process {
+		return &Process{
+			Script:     "app.js",
+			Env:        map[string]string{"A": "1", "DEBUG": "1"},
+			Envs:       map[string]map[string]string{"production": {"LOG_LEVEL": "warn"}},
+			EnvProfile: "production",
+			Labels:     map[string]string{"tier": "web"},
+		}
+	}
+
+	tests := []struct {
+		assignment  string
+		wantDisplay string
+		wantRestart bool
+		got         func(p *Process) interface{}
+		want        interface{}
+		wantErr     bool
+	}{
+		{"max_restarts=5", "5", false, func(p *Process) interface{} { return p.MaxRestarts }, 5, false},
+		{"min_uptime=30s", "30s", false, func(p *Process) interface{} { return p.MinUptime }, 30 * time.Second, false},
+		{"port=8080", "8080", true, func(p *Process) interface{} { return p.Port }, 8080, false},
+		{"command=npm start", "npm start", true, func(p *Process) interface{} { return []string{p.Command, p.Script} }, []string{"npm start", ""}, false},
+		{"args=--port 8080", "--port 8080", true, func(p *Process) interface{} { return p.Args }, []string{"--port", "8080"}, false},
+		{`args=["a b", "c"]`, `["a b", "c"]`, true, func(p *Process) interface{} { return p.Args }, []string{"a b", "c"}, false},
+		{"pre_start=", "", false, func(p *Process) interface{} { return p.PreStart }, "", false},
+		{"namespace=", "", false, func(p *Process) interface{} { return p.Namespace }, defaultNamespace, false},
+		{"env_profile=", "", true, func(p *Process) interface{} { return p.EnvProfile }, "", false},
+		{" max_restarts =3", "3", false, func(p *Process) interface{} { return p.MaxRestarts }, 3, false},
+
+		{"env.B=x=y", "x=y", true, func(p *Process) interface{} { return p.Env["B"] }, "x=y", false},
+		{"env.DEBUG", "(删除)", true, func(p *Process) interface{} { _, ok := p.Env["DEBUG"]; return ok }, false, false},
+		// 当前环境配置中定义的变量写入环境配置
+		{"env.LOG_LEVEL=debug", "debug", true, func(p *Process) interface{} {
+			return []string{p.Envs["production"]["LOG_LEVEL"], p.Env["LOG_LEVEL"]}
+		}, []string{"debug", ""}, false},
+		{"env.DB_PASSWORD=hunter2", secretMask, true, func(p *Process) interface{} { return p.Env["DB_PASSWORD"] }, "hunter2", false},
+		{"labels.team=payments", "payments", false, func(p *Process) interface{} { return p.Labels["team"] }, "payments", false},
+		{"labels.tier", "(删除)", false, func(p *Process) interface{} { return len(p.Labels) }, 0, false},
+
+		{"unknown=1", "", false, nil, nil, true},
+		{"max_restarts", "", false, nil, nil, true},
+		{"max_restarts=-1", "", false, nil, nil, true},
+		{"port=65536", "", false, nil, nil, true},
+		{"port=http", "", false, nil, nil, true},
+		{"min_uptime=soon", "", false, nil, nil, true},
+		{"script=", "", false, nil, nil, true},
+		{"args=[broken", "", false, nil, nil, true},
+		{"env_profile=staging", "", false, nil, nil, true},
+		{"watchdog_signal=SIGNOPE", "", false, nil, nil, true},
+		{"env.1BAD=x", "", false, nil, nil, true},
+		{"env.A=secret:vault:x", "", false, nil, nil, true},
+		{"labels.=x", "", false, nil, nil, true},
+		{"labels.a,b=x", "", false, nil, nil, true},
+	}
+
+	for _, tt := range tests {
+		p := newProcess()
+		a, err := parseSetAssignment(p, tt.assignment)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseSetAssignment(%q) error = %v, wantErr %v", tt.assignment, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if a.display != tt.wantDisplay || a.restart != tt.wantRestart {
+			t.Errorf("parseSetAssignment(%q) display = %q, restart = %v, want %q, %v", tt.assignment, a.display, a.restart, tt.wantDisplay, tt.wantRestart)
+		}
+		a.apply(p)
+		if got := tt.got(p); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseSetAssignment(%q) applied = %v, want %v", tt.assignment, got, tt.want)
+		}
+	}
+}
+
+func TestAssignedPort(t *testing.T) {
+	tests := []struct {
+		assignments []string
+		wantPort    int
+		wantFound   bool
+	}{
+		{nil, 0, false},
+		{[]string{"max_restarts=5"}, 0, false},
+		{[]string{"port=8080"}, 8080, true},
+		{[]string{" port =8080"}, 8080, true},
+		{[]string{"port=0"}, 0, true},
+		{[]string{"port=3000", "env.A=1", "port=4000"}, 4000, true},
+		{[]string{"port=http"}, 0, false},
+		{[]string{"port"}, 0, false},
+		{[]string{"env.port=80"}, 0, false},
+	}
+
+	for _, tt := range tests {
+		port, found := assignedPort(tt.assignments)
+		if port != tt.wantPort || found != tt.wantFound {
+			t.Errorf("assignedPort(%q) = %d, %v, want %d, %v", tt.assignments, port, found, tt.wantPort, tt.wantFound)
+		}
+	}
+}
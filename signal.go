@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// parseSignal 解析信号名称或编号，如 HUP、SIGUSR1、15，返回信号及其规范名称
+func parseSignal(name string) (syscall.Signal, string, error) {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if name == "" {
+		return 0, "", fmt.Errorf("未指定信号")
+	}
+
+	if num, err := strconv.Atoi(name); err == nil {
+		if num <= 0 {
+			return 0, "", fmt.Errorf("无效的信号编号: %d", num)
+		}
+		sig := syscall.Signal(num)
+		for signalName, s := range signalNames {
+			if s == sig {
+				return sig, signalName, nil
+			}
+		}
+		return sig, name, nil
+	}
+
+	if !strings.HasPrefix(name, "SIG") {
+		name = "SIG" + name
+	}
+	sig, exists := signalNames[name]
+	if !exists {
+		return 0, "", fmt.Errorf("不支持的信号: %s", name)
+	}
+	return sig, name, nil
+}
+
+// signalProcessInstance 向进程发送信号并记录事件
+func (pm *ProcessManager) signalProcessInstance(p *Process, sig syscall.Signal, sigName string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if !p.isRunning() {
+		return fmt.Errorf("进程 '%s' 当前状态为 %s，无法发送信号", p.Name, p.Status)
+	}
+
+	pids := p.runningPIDs()
+	if len(pids) == 0 {
+		return fmt.Errorf("进程 '%s' 没有正在运行的实例", p.Name)
+	}
+
+	for _, pid := range pids {
+		if err := sendSignal(pid, sig); err != nil {
+			pm.recordEvent(p, "signal", fmt.Sprintf("发送 %s 失败 (PID: %d): %v", sigName, pid, err))
+			return fmt.Errorf("发送 %s 失败 (PID: %d): %v", sigName, pid, err)
+		}
+	}
+
+	pm.recordEvent(p, "signal", fmt.Sprintf("发送信号 %s (PID: %v)", sigName, pids))
+	pm.saveProcesses()
+	return nil
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"net"
 	"os"
 	"os/exec"
 	"sync"
@@ -16,6 +17,10 @@ const (
 	StatusStopping ProcessStatus = "stopping"
 	StatusErrored  ProcessStatus = "errored"
 	StatusOneTime  ProcessStatus = "one-time"
+	StatusPaused   ProcessStatus = "paused"
+
+	// Go 应用编译失败
+	StatusBuildFailed ProcessStatus = "build-failed"
 )
 
 // ExecMode 执行模式
@@ -26,14 +31,33 @@ const (
 	ExecModeCluster ExecMode = "cluster"
 )
 
+// AppType 应用类型
+type AppType string
+
+const (
+	AppTypeService AppType = "service"
+	AppTypeJob     AppType = "job"
+)
+
+// OverlapPolicy 任务运行重叠策略
+type OverlapPolicy string
+
+const (
+	OverlapSkip  OverlapPolicy = "skip"
+	OverlapQueue OverlapPolicy = "queue"
+	OverlapAllow OverlapPolicy = "allow"
+)
+
 // Process 进程信息结构
 type Process struct {
 	ID          int               `json:"id"`
 	Name        string            `json:"name"`
 	Script      string            `json:"script"`
+	Command     string            `json:"command,omitempty"`
 	Args        []string          `json:"args"`
 	Cwd         string            `json:"cwd"`
 	Env         map[string]string `json:"env"`
+	EnvFile     []string          `json:"env_file,omitempty"`
 	Instances   int               `json:"instances"`
 	ExecMode    ExecMode          `json:"exec_mode"`
 	Status      ProcessStatus     `json:"status"`
@@ -49,14 +73,101 @@ type Process struct {
 	WatchIgnore []string          `json:"watch_ignore"`
 	MaxRestarts int               `json:"max_restarts"`
 	MinUptime   time.Duration     `json:"min_uptime"`
+	Type        AppType           `json:"type"`
+	Namespace   string            `json:"namespace"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	DependsOn   []string          `json:"depends_on,omitempty"`
+	Events      []ProcessEvent    `json:"events,omitempty"`
+
+	// 环境配置，EnvProfile 为当前使用的配置名称
+	Envs       map[string]map[string]string `json:"envs,omitempty"`
+	EnvProfile string                       `json:"env_profile,omitempty"`
+
+	// 运行用户和组
+	User   string   `json:"user,omitempty"`
+	Group  string   `json:"group,omitempty"`
+	Groups []string `json:"groups,omitempty"`
+
+	// 资源限制
+	Limits     *ResourceLimits `json:"limits,omitempty"`
+	Cgroup     *CgroupConfig   `json:"cgroup,omitempty"`
+	CgroupPath string          `json:"cgroup_path,omitempty"`
+
+	// 上次异常退出的原因，如 crash、oom、unhealthy、watchdog
+	ExitReason string `json:"exit_reason,omitempty"`
+
+	// 健康检查
+	HealthCheck    *HealthCheckConfig `json:"health_check,omitempty"`
+	Health         HealthStatus       `json:"health,omitempty"`
+	HealthFailures int                `json:"health_failures,omitempty"`
+	HealthMessage  string             `json:"health_message,omitempty"`
+
+	// 看门狗
+	WatchdogTimeout  time.Duration `json:"watchdog_timeout,omitempty"`
+	WatchdogSignal   string        `json:"watchdog_signal,omitempty"`
+	LastWatchdogPing time.Time     `json:"last_watchdog_ping,omitempty"`
+
+	// 声明的端口和实际监听的端口
+	Port  int          `json:"port,omitempty"`
+	Ports []ListenPort `json:"ports,omitempty"`
+
+	// 解释器，为空时根据 shebang 和扩展名自动选择
+	Interpreter     string   `json:"interpreter,omitempty"`
+	InterpreterArgs []string `json:"interpreter_args,omitempty"`
+
+	// 进程身份，用于守护进程重启后确认 PID 未被复用
+	ProcStartTime int64  `json:"proc_start_time,omitempty"`
+	Cmdline       string `json:"cmdline,omitempty"`
+
+	// 生命周期钩子
+	PreStart    string        `json:"pre_start,omitempty"`
+	PostStart   string        `json:"post_start,omitempty"`
+	PreStop     string        `json:"pre_stop,omitempty"`
+	PostStop    string        `json:"post_stop,omitempty"`
+	HookTimeout time.Duration `json:"hook_timeout,omitempty"`
+
+	// 任务字段
+	Schedule   string        `json:"schedule,omitempty"`
+	Overlap    OverlapPolicy `json:"overlap,omitempty"`
+	MaxRunTime time.Duration `json:"max_run_time,omitempty"`
+	KeepRuns   int           `json:"keep_runs,omitempty"`
+	NextRun    time.Time     `json:"next_run,omitempty"`
+	RunCount   int           `json:"run_count,omitempty"`
+	Runs       []*JobRun     `json:"runs,omitempty"`
 
 	// 内部字段
-	cmd         *exec.Cmd    `json:"-"`
-	mutex       sync.RWMutex `json:"-"`
-	logWriter   *os.File     `json:"-"`
-	errorWriter *os.File     `json:"-"`
-	cancelFunc  func()       `json:"-"`
-	watcherStop chan bool    `json:"-"`
+	cmd         *exec.Cmd             `json:"-"`
+	mutex       sync.RWMutex          `json:"-"`
+	logWriter   *os.File              `json:"-"`
+	errorWriter *os.File              `json:"-"`
+	cancelFunc  func()                `json:"-"`
+	watcherStop chan bool             `json:"-"`
+	activeRuns  map[int]*jobRunHandle `json:"-"`
+	queuedRuns  int                   `json:"-"`
+	exited      chan struct{}         `json:"-"` // watchProcess 等到进程退出后关闭
+
+	// 钩子执行期间不持有锁，用于防止重复启动和启动已停止或删除的应用
+	starting int  `json:"-"` // 正在执行 pre_start 钩子的启动次数
+	stops    int  `json:"-"` // 累计停止次数
+	deleted  bool `json:"-"`
+
+	// 健康检查调度
+	healthChecking  bool      `json:"-"`
+	healthNextCheck time.Time `json:"-"`
+	healthFired     time.Time `json:"-"` // 连续失败后发送 SIGTERM 的时间
+
+	// 解析后的调度表达式，Schedule 变化时重新解析
+	schedule     *CronSchedule `json:"-"`
+	scheduleExpr string        `json:"-"`
+
+	// 看门狗通知 socket 和发送超时信号的时间
+	notifyConn    *net.UnixConn `json:"-"`
+	watchdogFired time.Time     `json:"-"`
+
+	// cgroup 统计
+	oomKills         int       `json:"-"`
+	cgroupCPUUsec    uint64    `json:"-"`
+	cgroupSampleTime time.Time `json:"-"`
 }
 
 // Config 配置文件结构
@@ -67,10 +178,12 @@ type Config struct {
 // AppConfig 应用配置
 type AppConfig struct {
 	Name        string            `json:"name" yaml:"name"`
-	Script      string            `json:"script" yaml:"script"`
+	Script      string            `json:"script,omitempty" yaml:"script,omitempty"`
+	Command     string            `json:"command,omitempty" yaml:"command,omitempty"`
 	Args        []string          `json:"args,omitempty" yaml:"args,omitempty"`
 	Cwd         string            `json:"cwd,omitempty" yaml:"cwd,omitempty"`
 	Env         map[string]string `json:"env,omitempty" yaml:"env,omitempty"`
+	EnvFile     []string          `json:"env_file,omitempty" yaml:"env_file,omitempty"`
 	Instances   int               `json:"instances,omitempty" yaml:"instances,omitempty"`
 	ExecMode    string            `json:"exec_mode,omitempty" yaml:"exec_mode,omitempty"`
 	Watch       bool              `json:"watch,omitempty" yaml:"watch,omitempty"`
@@ -79,6 +192,62 @@ type AppConfig struct {
 	ErrorFile   string            `json:"error_file,omitempty" yaml:"error_file,omitempty"`
 	MaxRestarts int               `json:"max_restarts,omitempty" yaml:"max_restarts,omitempty"`
 	MinUptime   string            `json:"min_uptime,omitempty" yaml:"min_uptime,omitempty"`
+	Type        string            `json:"type,omitempty" yaml:"type,omitempty"`
+	Namespace   string            `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Schedule    string            `json:"schedule,omitempty" yaml:"schedule,omitempty"`
+	Overlap     string            `json:"overlap,omitempty" yaml:"overlap,omitempty"`
+	MaxRunTime  string            `json:"max_run_time,omitempty" yaml:"max_run_time,omitempty"`
+	KeepRuns    int               `json:"keep_runs,omitempty" yaml:"keep_runs,omitempty"`
+	DependsOn   []string          `json:"depends_on,omitempty" yaml:"depends_on,omitempty"`
+	PreStart    string            `json:"pre_start,omitempty" yaml:"pre_start,omitempty"`
+	PostStart   string            `json:"post_start,omitempty" yaml:"post_start,omitempty"`
+	PreStop     string            `json:"pre_stop,omitempty" yaml:"pre_stop,omitempty"`
+	PostStop    string            `json:"post_stop,omitempty" yaml:"post_stop,omitempty"`
+	HookTimeout string            `json:"hook_timeout,omitempty" yaml:"hook_timeout,omitempty"`
+
+	Envs       map[string]map[string]string `json:"envs,omitempty" yaml:"envs,omitempty"`
+	EnvProfile string                       `json:"env_profile,omitempty" yaml:"env_profile,omitempty"`
+
+	Interpreter     string   `json:"interpreter,omitempty" yaml:"interpreter,omitempty"`
+	InterpreterArgs []string `json:"interpreter_args,omitempty" yaml:"interpreter_args,omitempty"`
+
+	User   string   `json:"user,omitempty" yaml:"user,omitempty"`
+	Group  string   `json:"group,omitempty" yaml:"group,omitempty"`
+	Groups []string `json:"groups,omitempty" yaml:"groups,omitempty"`
+
+	Limits *ResourceLimits `json:"limits,omitempty" yaml:"limits,omitempty"`
+	Cgroup *CgroupConfig   `json:"cgroup,omitempty" yaml:"cgroup,omitempty"`
+
+	HealthCheck *HealthCheckConfig `json:"health_check,omitempty" yaml:"health_check,omitempty"`
+
+	WatchdogTimeout string `json:"watchdog_timeout,omitempty" yaml:"watchdog_timeout,omitempty"`
+	WatchdogSignal  string `json:"watchdog_signal,omitempty" yaml:"watchdog_signal,omitempty"`
+
+	Port int `json:"port,omitempty" yaml:"port,omitempty"`
+}
+
+// 每个进程保留的事件数量
+const maxProcessEvents = 100
+
+// ProcessEvent 进程事件记录
+type ProcessEvent struct {
+	Time    time.Time `json:"time"`
+	Type    string    `json:"type"`
+	Message string    `json:"message"`
+}
+
+// JobRun 任务单次运行结果
+type JobRun struct {
+	ID        int           `json:"id"`
+	Trigger   string        `json:"trigger"`
+	StartTime time.Time     `json:"start_time"`
+	EndTime   time.Time     `json:"end_time"`
+	Duration  time.Duration `json:"duration"`
+	ExitCode  int           `json:"exit_code"`
+	Error     string        `json:"error,omitempty"`
+	LogFile   string        `json:"log_file"`
+	ErrorFile string        `json:"error_file"`
 }
 
 // ProcessManager 进程管理器
@@ -87,6 +256,14 @@ type ProcessManager struct {
 	nextID    int
 	mutex     sync.RWMutex
 	dataDir   string
+
+	// 存放应用 cgroup 的目录，为空表示 cgroup v2 不可用
+	cgroupBase string
+
+	// 进程列表最近一次使用的端口快照
+	portsMutex sync.Mutex
+	ports      *portSnapshot
+	portsTime  time.Time
 }
 
 // LogEntry 日志条目
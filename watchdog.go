@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// 看门狗超时后默认发送的信号
+const defaultWatchdogSignal = "SIGKILL"
+
+// 发送看门狗信号后仍未退出时，等待多久再强制结束
+const watchdogKillGrace = 5 * time.Second
+
+// notifySocketDir 返回存放通知 socket 的运行时目录
+// 以 root 运行时数据目录位于 /root 下，以 user 指定的用户运行的应用无法访问，改用 /run/gopm2
+func (pm *ProcessManager) notifySocketDir() string {
+	if os.Geteuid() == 0 {
+		return "/run/gopm2"
+	}
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "gopm2")
+	}
+	return filepath.Join(pm.dataDir, "notify")
+}
+
+// notifySocketPath 返回应用的通知 socket 路径，每个应用一个属于运行用户的目录，按 ID 命名以避免路径过长
+func (pm *ProcessManager) notifySocketPath(p *Process) string {
+	return filepath.Join(pm.notifySocketDir(), strconv.Itoa(p.ID), "notify.sock")
+}
+
+// openWatchdog 创建应用的通知 socket，返回需要传给应用的 NOTIFY_SOCKET 和 WATCHDOG_USEC
+// 应用按 systemd 的 sd_notify 协议发送 WATCHDOG=1 心跳，启动时间视为第一次心跳（调用方需持有 p.mutex）
+func (pm *ProcessManager) openWatchdog(p *Process) ([]string, error) {
+	path := pm.notifySocketPath(p)
+
+	if p.notifyConn == nil {
+		// 运行时目录允许所有用户进入，应用目录只允许运行应用的用户访问
+		cred, err := p.processCredential()
+		if err != nil {
+			return nil, err
+		}
+		appDir := filepath.Dir(path)
+		os.MkdirAll(filepath.Dir(appDir), 0755)
+		if err := os.MkdirAll(appDir, 0700); err != nil {
+			return nil, fmt.Errorf("创建看门狗通知目录失败: %v", err)
+		}
+		os.Chmod(appDir, 0700)
+		chownFile(appDir, cred)
+		os.Remove(path)
+
+		conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+		if err != nil {
+			return nil, fmt.Errorf("创建看门狗通知 socket 失败: %v", err)
+		}
+
+		// 只允许运行应用的用户发送心跳
+		os.Chmod(path, 0600)
+		chownFile(path, cred)
+
+		p.notifyConn = conn
+		go pm.readWatchdogPings(p, conn)
+	}
+
+	p.LastWatchdogPing = time.Now()
+	p.watchdogFired = time.Time{}
+
+	return []string{
+		"NOTIFY_SOCKET=" + path,
+		fmt.Sprintf("WATCHDOG_USEC=%d", p.WatchdogTimeout.Microseconds()),
+	}, nil
+}
+
+// closeWatchdog 关闭应用的通知 socket（调用方需持有 p.mutex）
+func (pm *ProcessManager) closeWatchdog(p *Process) {
+	if p.notifyConn == nil {
+		return
+	}
+	p.notifyConn.Close()
+	p.notifyConn = nil
+	path := pm.notifySocketPath(p)
+	os.Remove(path)
+	os.Remove(filepath.Dir(path))
+}
+
+// readWatchdogPings 读取通知 socket 中的消息，收到 WATCHDOG=1 时更新心跳时间
+func (pm *ProcessManager) readWatchdogPings(p *Process, conn *net.UnixConn) {
+	buf := make([]byte, 4096)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+
+		for _, line := range strings.Split(string(buf[:n]), "\n") {
+			if strings.TrimSpace(line) != "WATCHDOG=1" {
+				continue
+			}
+			p.mutex.Lock()
+			if p.notifyConn == conn {
+				p.LastWatchdogPing = time.Now()
+			}
+			p.mutex.Unlock()
+		}
+	}
+}
+
+// watchdogLoop 检查应用的心跳，超时后发送配置的信号，进程退出后由 watchProcess 按 watchdog 原因重启
+func (pm *ProcessManager) watchdogLoop() {
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		pm.mutex.RLock()
+		processes := make([]*Process, 0)
+		for _, p := range pm.processes {
+			if p.WatchdogTimeout > 0 {
+				processes = append(processes, p)
+			}
+		}
+		pm.mutex.RUnlock()
+
+		for _, p := range processes {
+			pm.checkWatchdog(p, now)
+		}
+	}
+}
+
+// checkWatchdog 检查单个应用是否超过看门狗超时时间，暂停的应用不检查
+func (pm *ProcessManager) checkWatchdog(p *Process, now time.Time) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.Status != StatusOnline || p.PID <= 0 {
+		return
+	}
+
+	// 已发送信号但进程仍未退出，强制结束
+	if !p.watchdogFired.IsZero() {
+		if now.Sub(p.watchdogFired) >= watchdogKillGrace {
+			sendSignal(p.PID, syscall.SIGKILL)
+			p.watchdogFired = now
+		}
+		return
+	}
+
+	if now.Sub(p.LastWatchdogPing) < p.WatchdogTimeout {
+		return
+	}
+
+	signalName := p.WatchdogSignal
+	if signalName == "" {
+		signalName = defaultWatchdogSignal
+	}
+	sig, signalName, err := parseSignal(signalName)
+	if err != nil {
+		sig, signalName = syscall.SIGKILL, defaultWatchdogSignal
+	}
+
+	p.watchdogFired = now
+	pm.recordEvent(p, "watchdog", fmt.Sprintf("超过 %s 未收到心跳，发送 %s (PID: %d)", p.WatchdogTimeout, signalName, p.PID))
+	if err := sendSignal(p.PID, sig); err != nil {
+		pm.recordEvent(p, "watchdog", fmt.Sprintf("发送 %s 失败: %v", signalName, err))
+	}
+}